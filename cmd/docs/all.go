@@ -0,0 +1,235 @@
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/appilon/tfplugin/util"
+)
+
+// Sidebar of the provider's pages on terraform.io
+var sidebarTmpl = `<% wrap_layout :inner do %>
+  <% content_for :sidebar do %>
+    <div class="docs-sidebar hidden-print affix-top" role="complementary">
+      <ul class="nav docs-sidenav">
+        <li<%= sidebar_current("docs-home") %>>
+          <a href="/docs/providers/index.html">All Providers</a>
+        </li>
+
+        <li<%= sidebar_current("docs-{{.Provider}}-index") %>>
+          <a href="/docs/providers/{{.Provider}}/index.html">{{.Title}} Provider</a>
+        </li>
+{{- if .DataSources}}
+
+        <li<%= sidebar_current("docs-{{.Provider}}-datasource") %>>
+          <a href="#">Data Sources</a>
+          <ul class="nav nav-visible">
+{{- range .DataSources}}
+            <li<%= sidebar_current("{{.SidebarCurrent}}") %>>
+              <a href="{{.Href}}">{{.Name}}</a>
+            </li>
+{{- end}}
+          </ul>
+        </li>
+{{- end}}
+{{- if .Resources}}
+
+        <li<%= sidebar_current("docs-{{.Provider}}-resource") %>>
+          <a href="#">Resources</a>
+          <ul class="nav nav-visible">
+{{- range .Resources}}
+            <li<%= sidebar_current("{{.SidebarCurrent}}") %>>
+              <a href="{{.Href}}">{{.Name}}</a>
+            </li>
+{{- end}}
+          </ul>
+        </li>
+{{- end}}
+      </ul>
+    </div>
+  <% end %>
+
+  <%= yield %>
+<% end %>
+`
+
+type SidebarLink struct {
+	Name           string
+	Href           string
+	SidebarCurrent string
+}
+
+type SidebarDoc struct {
+	Provider    string
+	Title       string
+	DataSources []*SidebarLink
+	Resources   []*SidebarLink
+}
+
+// Every page of a provider in one document, what -all writes for
+// -format=json
+type ProviderDocs struct {
+	Provider    *ResourceDoc   `json:"provider,omitempty"`
+	Provisioner *ResourceDoc   `json:"provisioner,omitempty"`
+	DataSources []*ResourceDoc `json:"data_sources,omitempty"`
+	Resources   []*ResourceDoc `json:"resources,omitempty"`
+}
+
+// Writes every page of the plugin under out laid out for format, the
+// website's directory also gets the sidebar. json is written to docs.json.
+func writeAll(envelope *schema.DumpEnvelope, out, format, order string, templates *Templates) error {
+	if format == formatJSON {
+		return writeFile(filepath.Join(out, "docs.json"), func(w io.Writer) error {
+			docs, err := newProviderDocs(envelope, order)
+			if err != nil {
+				return err
+			}
+			return writeJSON(w, docs)
+		})
+	}
+	l := layouts[format]
+
+	if envelope.Provisioner != nil {
+		name, err := util.GetPackageName(envelope.Provider)
+		if err != nil {
+			return err
+		}
+		return writePage(templates, l.path(out, kindProvisioner, name),
+			NewResourceDoc(kindProvisioner, name, &schema.ResourceDump{Schema: envelope.Provisioner.Schema}, order))
+	}
+
+	provider := ProviderName(envelope)
+	p := envelope.Schema
+
+	if err := writePage(templates, l.path(out, kindProvider, ""),
+		NewResourceDoc(kindProvider, provider, &schema.ResourceDump{Schema: p.Schema}, order)); err != nil {
+		return err
+	}
+
+	sidebar := &SidebarDoc{
+		Provider: provider,
+		Title:    strings.Title(provider),
+	}
+	for _, name := range sortedNames(p.DataSourcesMap) {
+		page := pageName(name, provider)
+		if err := writePage(templates, l.path(out, kindDataSource, page), NewResourceDoc(kindDataSource, name, p.DataSourcesMap[name], order)); err != nil {
+			return err
+		}
+		sidebar.DataSources = append(sidebar.DataSources, &SidebarLink{
+			Name:           name,
+			Href:           fmt.Sprintf("/docs/providers/%s/d/%s.html", provider, page),
+			SidebarCurrent: fmt.Sprintf("docs-%s-datasource-%s", provider, sidebarName(name, provider)),
+		})
+	}
+	for _, name := range sortedNames(p.ResourcesMap) {
+		page := pageName(name, provider)
+		if err := writePage(templates, l.path(out, kindResource, page), NewResourceDoc(kindResource, name, p.ResourcesMap[name], order)); err != nil {
+			return err
+		}
+		sidebar.Resources = append(sidebar.Resources, &SidebarLink{
+			Name:           name,
+			Href:           fmt.Sprintf("/docs/providers/%s/r/%s.html", provider, page),
+			SidebarCurrent: fmt.Sprintf("docs-%s-resource-%s", provider, sidebarName(name, provider)),
+		})
+	}
+
+	if !l.sidebar {
+		return nil
+	}
+	return writeFile(filepath.Join(out, provider+".erb"), func(w io.Writer) error {
+		t, err := template.New("sidebar").Parse(sidebarTmpl)
+		if err != nil {
+			return err
+		}
+		return t.Execute(w, sidebar)
+	})
+}
+
+func newProviderDocs(envelope *schema.DumpEnvelope, order string) (*ProviderDocs, error) {
+	if envelope.Provisioner != nil {
+		name, err := util.GetPackageName(envelope.Provider)
+		if err != nil {
+			return nil, err
+		}
+		return &ProviderDocs{
+			Provisioner: NewResourceDoc(kindProvisioner, name, &schema.ResourceDump{Schema: envelope.Provisioner.Schema}, order),
+		}, nil
+	}
+
+	p := envelope.Schema
+	docs := &ProviderDocs{
+		Provider: NewResourceDoc(kindProvider, ProviderName(envelope), &schema.ResourceDump{Schema: p.Schema}, order),
+	}
+	for _, name := range sortedNames(p.DataSourcesMap) {
+		docs.DataSources = append(docs.DataSources, NewResourceDoc(kindDataSource, name, p.DataSourcesMap[name], order))
+	}
+	for _, name := range sortedNames(p.ResourcesMap) {
+		docs.Resources = append(docs.Resources, NewResourceDoc(kindResource, name, p.ResourcesMap[name], order))
+	}
+	return docs, nil
+}
+
+func writePage(templates *Templates, path string, doc *ResourceDoc) error {
+	doc.Subcategory = subcategory(path)
+	return writeFile(path, func(w io.Writer) error {
+		return templates.Print(w, doc)
+	})
+}
+
+// Files are only written when their content changed, so regenerating docs
+// leaves untouched pages alone
+func writeFile(path string, write func(io.Writer) error) error {
+	var b bytes.Buffer
+	if err := write(&b); err != nil {
+		return fmt.Errorf("writing %s: %s", path, err)
+	}
+	if existing, err := ioutil.ReadFile(path); err == nil && bytes.Equal(existing, b.Bytes()) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b.Bytes(), 0644)
+}
+
+// The provider is named by the plugin naming convention, dumps without an
+// envelope are named after the prefix of their resources
+func ProviderName(envelope *schema.DumpEnvelope) string {
+	if name, err := util.GetPackageName(envelope.Provider); err == nil {
+		return strings.SplitN(name, "_", 2)[0]
+	}
+	var names []string
+	for name := range envelope.Schema.ResourcesMap {
+		names = append(names, name)
+	}
+	for name := range envelope.Schema.DataSourcesMap {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return "provider"
+	}
+	sort.Strings(names)
+	return strings.SplitN(names[0], "_", 2)[0]
+}
+
+// netlify_build_hook is documented in build_hook.html.markdown
+func pageName(name, provider string) string {
+	return strings.TrimPrefix(name, provider+"_")
+}
+
+func sortedNames(m map[string]*schema.ResourceDump) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,210 @@
+package docs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/appilon/tfplugin/schema"
+)
+
+// Kinds of drift between the website and the schema
+const (
+	driftMissingPage  = "missing-page"
+	driftStalePage    = "stale-page"
+	driftUndocumented = "undocumented"
+	driftStale        = "stale"
+	driftRequirement  = "requirement-mismatch"
+)
+
+type drift struct {
+	Path    string
+	Kind    string
+	Message string
+}
+
+func (d *drift) String() string {
+	return fmt.Sprintf("[%s] %s: %s", d.Kind, d.Path, d.Message)
+}
+
+// * `name` - (Required) ... or - `name` - (Optional, ForceNew) ...
+var bulletRegexp = regexp.MustCompile("^\\s*[*-]\\s+`([^`]+)`\\s*-?\\s*(?:\\((Required|Optional)[^)]*\\))?")
+
+// Compares the argument and attribute lists of the pages under website/docs
+// laid out for format with the dump, nested blocks are matched by name at any
+// depth
+func checkAll(envelope *schema.DumpEnvelope, website, format string) ([]*drift, error) {
+	if envelope.Schema == nil {
+		return nil, errors.New("only provider docs can be checked")
+	}
+	l, ok := layouts[format]
+	if !ok {
+		return nil, fmt.Errorf("%s docs can't be checked", format)
+	}
+	provider := ProviderName(envelope)
+
+	var drifts []*drift
+	for _, section := range []struct {
+		kind      string
+		prefix    string
+		resources map[string]*schema.ResourceDump
+	}{
+		{kindResource, "resource", envelope.Schema.ResourcesMap},
+		{kindDataSource, "data", envelope.Schema.DataSourcesMap},
+	} {
+		dir := filepath.Join(website, "docs", l.dirs[section.kind])
+		pages, err := findPages(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range sortedNames(section.resources) {
+			path := section.prefix + "." + name
+			page, ok := pages[pageName(name, provider)]
+			if !ok {
+				drifts = append(drifts, &drift{path, driftMissingPage, fmt.Sprintf("no page in %s", dir)})
+				continue
+			}
+			delete(pages, pageName(name, provider))
+
+			docs, err := parsePage(page)
+			if err != nil {
+				return nil, err
+			}
+			drifts = append(drifts, compare(path, page, docs, section.resources[name])...)
+		}
+
+		// pages left over document resources that are gone
+		var stale []string
+		for page := range pages {
+			stale = append(stale, page)
+		}
+		sort.Strings(stale)
+		for _, page := range stale {
+			drifts = append(drifts, &drift{
+				Path:    section.prefix + "." + provider + "_" + page,
+				Kind:    driftStalePage,
+				Message: fmt.Sprintf("%s documents a %s that no longer exists", pages[page], section.prefix),
+			})
+		}
+	}
+
+	return drifts, nil
+}
+
+// Pages in dir by their name without extension
+func findPages(dir string) (map[string]string, error) {
+	pages := make(map[string]string)
+	for _, pattern := range []string{"*.html.markdown", "*.html.md", "*.markdown", "*.md"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			name := strings.SplitN(filepath.Base(match), ".", 2)[0]
+			if _, ok := pages[name]; !ok {
+				pages[name] = match
+			}
+		}
+	}
+	return pages, nil
+}
+
+// Collects the bullets under the Argument and Attributes Reference headings
+// with their Required or Optional, sections like Timeouts and Import are
+// skipped
+func parsePage(path string) (map[string][]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	docs := make(map[string][]string)
+	var inArguments, inAttributes bool
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "## ") {
+			heading := strings.ToLower(line)
+			inArguments = strings.Contains(heading, "argument")
+			inAttributes = strings.Contains(heading, "attribute")
+			continue
+		}
+		if !inArguments && !inAttributes {
+			continue
+		}
+
+		match := bulletRegexp.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		docs[match[1]] = append(docs[match[1]], match[2])
+	}
+	return docs, scanner.Err()
+}
+
+func compare(prefix, page string, docs map[string][]string, resource *schema.ResourceDump) []*drift {
+	var drifts []*drift
+
+	flattened := make(map[string][]*schema.SchemaDump)
+	var walk func(path string, schemas map[string]*schema.SchemaDump)
+	walk = func(path string, schemas map[string]*schema.SchemaDump) {
+		for _, key := range sortedArguments(schemas, orderAlphabetical) {
+			s := schemas[key]
+			flattened[key] = append(flattened[key], s)
+			if _, ok := docs[key]; !ok {
+				kind := "argument"
+				if s.Computed && !s.Optional && !s.Required {
+					kind = "attribute"
+				}
+				drifts = append(drifts, &drift{path + "." + key, driftUndocumented, fmt.Sprintf("%s is not documented in %s", kind, page)})
+			}
+			if elem, ok := s.Elem.(*schema.ResourceDump); ok {
+				walk(path+"."+key, elem.Schema)
+			}
+		}
+	}
+	walk(prefix, resource.Schema)
+
+	var names []string
+	for name := range docs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schemas, ok := flattened[name]
+		if !ok {
+			// id is exported by every resource and data source without being declared
+			if name == "id" {
+				continue
+			}
+			drifts = append(drifts, &drift{prefix + "." + name, driftStale, fmt.Sprintf("documented in %s but not in the schema", page)})
+			continue
+		}
+
+		for _, requirement := range docs[name] {
+			if requirement == "" || matchesRequirement(schemas, requirement) {
+				continue
+			}
+			drifts = append(drifts, &drift{prefix + "." + name, driftRequirement, fmt.Sprintf("documented as %s in %s", requirement, page)})
+		}
+	}
+
+	return drifts
+}
+
+// Any schema of that name agreeing is enough, nested blocks may reuse names
+func matchesRequirement(schemas []*schema.SchemaDump, requirement string) bool {
+	for _, s := range schemas {
+		if s.Required == (requirement == "Required") {
+			return true
+		}
+	}
+	return false
+}
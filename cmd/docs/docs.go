@@ -1,12 +1,13 @@
 package docs
 
 import (
-	"encoding/json"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/appilon/tfplugin/schema"
+	"github.com/appilon/tfplugin/util"
 	"github.com/mitchellh/cli"
 )
 
@@ -30,27 +31,119 @@ func (c *command) Run(args []string) int {
 	flags := flag.NewFlagSet(CommandName, flag.ExitOnError)
 	var datasource string
 	var resource string
+	var all bool
+	var check bool
+	var validateExamples bool
+	var out string
+	var format string
+	var order string
+	var templatePath string
+	var resourceTemplate, datasourceTemplate, indexTemplate, provisionerTemplate string
 	flags.StringVar(&datasource, "datasource", "", "data source name")
 	flags.StringVar(&resource, "resource", "", "resource name")
+	flags.BoolVar(&all, "all", false, "write the pages of every resource and data source, the index and the sidebar")
+	flags.BoolVar(&check, "check", false, "compare the pages under -out with the schema")
+	flags.BoolVar(&validateExamples, "validate-examples", false, "check the hcl examples of the pages under -out against the schema")
+	flags.StringVar(&out, "out", "website", "website directory -all writes to and -check reads")
+	flags.StringVar(&format, "format", formatWebsite, "website, registry or json")
+	flags.StringVar(&order, "sort", orderAlphabetical, "order of arguments and attributes: alphabetical, required-first or source")
+	flags.StringVar(&templatePath, "template", "", "template replacing the built in one for every page")
+	flags.StringVar(&resourceTemplate, "resource-template", "", "template for resource pages, overrides -template")
+	flags.StringVar(&datasourceTemplate, "datasource-template", "", "template for data source pages, overrides -template")
+	flags.StringVar(&indexTemplate, "index-template", "", "template for the provider index page, overrides -template")
+	flags.StringVar(&provisionerTemplate, "provisioner-template", "", "template for provisioner pages, overrides -template")
 	flags.Parse(args)
 
-	provider := &schema.ProviderDump{}
-	err := json.NewDecoder(os.Stdin).Decode(provider)
+	if _, ok := layouts[format]; !ok && format != formatJSON {
+		log.Printf("Error unknown format %q, must be %s, %s or %s", format, formatWebsite, formatRegistry, formatJSON)
+		return 1
+	}
+
+	switch order {
+	case orderAlphabetical, orderRequiredFirst, orderSource:
+	default:
+		log.Printf("Error unknown sort %q, must be %s, %s or %s", order, orderAlphabetical, orderRequiredFirst, orderSource)
+		return 1
+	}
+
+	envelope, err := schema.ReadProviderDump(os.Stdin)
 	if err != nil {
 		log.Printf("Error decoding provider json: %s", err)
 		return 1
 	}
 
+	templates, err := NewTemplates(format, templatePath, map[string]string{
+		kindResource:    resourceTemplate,
+		kindDataSource:  datasourceTemplate,
+		kindProvider:    indexTemplate,
+		kindProvisioner: provisionerTemplate,
+	})
+	if err != nil {
+		log.Printf("Error loading templates: %s", err)
+		return 1
+	}
+
+	if check || validateExamples {
+		var drifts []*drift
+		if check {
+			d, err := checkAll(envelope, out, format)
+			if err != nil {
+				log.Printf("Error checking docs: %s", err)
+				return 1
+			}
+			drifts = append(drifts, d...)
+		}
+		if validateExamples {
+			d, err := checkExamples(envelope, out)
+			if err != nil {
+				log.Printf("Error validating examples: %s", err)
+				return 1
+			}
+			drifts = append(drifts, d...)
+		}
+		for _, d := range drifts {
+			fmt.Println(d)
+		}
+		if len(drifts) > 0 {
+			fmt.Printf("%d problems found in %s\n", len(drifts), out)
+			return 1
+		}
+		return 0
+	}
+
+	if all {
+		if err := writeAll(envelope, out, format, order, templates); err != nil {
+			log.Printf("Error writing docs: %s", err)
+			return 1
+		}
+		return 0
+	}
+
+	// a provisioner is documented like a single resource
+	if envelope.Provisioner != nil {
+		name, err := util.GetPackageName(envelope.Provider)
+		if err != nil {
+			log.Printf("Error determining provisioner name: %s", err)
+			return 1
+		}
+		if err := templates.Print(os.Stdout, NewResourceDoc(kindProvisioner, name, &schema.ResourceDump{Schema: envelope.Provisioner.Schema}, order)); err != nil {
+			log.Printf("Error printing doc for provisioner: %s", err)
+			return 1
+		}
+		return 0
+	}
+	provider := envelope.Schema
+
 	var resourceMap map[string]*schema.ResourceDump
 	var mapType string
 	var name string
 	if datasource != "" {
 		resourceMap = provider.DataSourcesMap
-		mapType = "data source"
+		mapType = kindDataSource
 		name = datasource
 	} else if resource != "" {
 		resourceMap = provider.ResourcesMap
-		mapType = "resource"
+		mapType = kindResource
 		name = resource
 	} else {
 		return cli.RunResultHelp
@@ -62,7 +155,7 @@ func (c *command) Run(args []string) int {
 		return 1
 	}
 
-	if err := PrintDoc(r); err != nil {
+	if err := templates.Print(os.Stdout, NewResourceDoc(mapType, name, r, order)); err != nil {
 		log.Printf("Error printing doc for %s: %s", name, err)
 		return 1
 	}
@@ -0,0 +1,22 @@
+package docs
+
+import (
+	"github.com/appilon/tfplugin/schema"
+)
+
+// Minimal configuration setting every required argument, nested resources
+// are written as blocks
+func exampleConfig(kind, name string, resource *schema.ResourceDump) string {
+	var opts schema.ExampleOptions
+	switch kind {
+	case kindResource:
+		return schema.ExampleResource(name, resource, opts)
+	case kindDataSource:
+		return schema.ExampleDataSource(name, resource, opts)
+	case kindProvider:
+		return schema.ExampleProvider(name, resource.Schema, opts)
+	case kindProvisioner:
+		return schema.ExampleProvisioner(name, resource.Schema, opts)
+	}
+	return ""
+}
@@ -0,0 +1,253 @@
+package docs
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/hashicorp/hcl2/hcl"
+	"github.com/hashicorp/hcl2/hcl/hclsyntax"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
+)
+
+// Kinds of problems in the examples of the website
+const (
+	driftInvalidHCL       = "invalid-hcl"
+	driftUnknownResource  = "unknown-resource"
+	driftUnknownArgument  = "unknown-argument"
+	driftMissingArgument  = "missing-argument"
+	driftComputedArgument = "computed-argument"
+	driftBlockMismatch    = "block-mismatch"
+)
+
+// Set on any resource or data source by Terraform itself
+var metaArguments = map[string]bool{
+	"count":      true,
+	"depends_on": true,
+	"for_each":   true,
+	"provider":   true,
+}
+
+var resourceMetaBlocks = map[string]bool{
+	"connection":  true,
+	"dynamic":     true,
+	"lifecycle":   true,
+	"provisioner": true,
+}
+
+type codeBlock struct {
+	// line of the first line of code
+	Line int
+	Code []byte
+}
+
+// Checks the resource and data blocks of every hcl example in the markdown
+// under website/docs against the dump, blocks of other providers are skipped
+func checkExamples(envelope *schema.DumpEnvelope, website string) ([]*drift, error) {
+	if envelope.Schema == nil {
+		return nil, errors.New("only provider docs can be checked")
+	}
+	v := &exampleValidator{
+		provider: ProviderName(envelope),
+		dump:     envelope.Schema,
+	}
+
+	var pages []string
+	err := filepath.Walk(filepath.Join(website, "docs"), func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && (strings.HasSuffix(path, ".markdown") || strings.HasSuffix(path, ".md")) {
+			pages = append(pages, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(pages)
+
+	for _, page := range pages {
+		blocks, err := codeBlocks(page)
+		if err != nil {
+			return nil, err
+		}
+		for _, block := range blocks {
+			v.example(page, block)
+		}
+	}
+	return v.drifts, nil
+}
+
+// Fenced code blocks tagged hcl or terraform
+func codeBlocks(path string) ([]*codeBlock, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var blocks []*codeBlock
+	var current *codeBlock
+	line := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		trimmed := strings.TrimSpace(text)
+		if !strings.HasPrefix(trimmed, fence) {
+			if current != nil {
+				current.Code = append(current.Code, text+"\n"...)
+			}
+			continue
+		}
+
+		if current != nil {
+			blocks = append(blocks, current)
+			current = nil
+			continue
+		}
+		switch strings.TrimSpace(strings.TrimPrefix(trimmed, fence)) {
+		case "hcl", "terraform", "tf":
+			current = &codeBlock{Line: line + 1}
+		default:
+			// skip to the closing fence of other languages
+			for scanner.Scan() {
+				line++
+				if strings.HasPrefix(strings.TrimSpace(scanner.Text()), fence) {
+					break
+				}
+			}
+		}
+	}
+	return blocks, scanner.Err()
+}
+
+type exampleValidator struct {
+	provider string
+	dump     *schema.ProviderDump
+	drifts   []*drift
+}
+
+func (v *exampleValidator) add(page string, r hcl.Range, kind, format string, a ...interface{}) {
+	v.drifts = append(v.drifts, &drift{
+		Path:    fmt.Sprintf("%s:%d", page, r.Start.Line),
+		Kind:    kind,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+func (v *exampleValidator) example(page string, block *codeBlock) {
+	file, diags := hclsyntax.ParseConfig(block.Code, page, hcl.Pos{Line: block.Line, Column: 1})
+	if diags.HasErrors() {
+		for _, diag := range diags {
+			r := hcl.Range{Start: hcl.Pos{Line: block.Line}}
+			if diag.Subject != nil {
+				r = *diag.Subject
+			}
+			v.add(page, r, driftInvalidHCL, "%s: %s", diag.Summary, diag.Detail)
+		}
+		return
+	}
+
+	for _, b := range file.Body.(*hclsyntax.Body).Blocks {
+		if len(b.Labels) != 2 || !strings.HasPrefix(b.Labels[0], v.provider+"_") {
+			continue
+		}
+		name := b.Labels[0]
+
+		var resources map[string]*schema.ResourceDump
+		var noun string
+		switch b.Type {
+		case "resource":
+			resources, noun = v.dump.ResourcesMap, "resource"
+		case "data":
+			resources, noun = v.dump.DataSourcesMap, "data source"
+		default:
+			continue
+		}
+
+		r, ok := resources[name]
+		if !ok {
+			v.add(page, b.LabelRanges[0], driftUnknownResource, "%s is not a %s", name, noun)
+			continue
+		}
+		metaBlocks := map[string]bool{}
+		if b.Type == "resource" {
+			metaBlocks = resourceMetaBlocks
+			if r.Timeouts != nil {
+				metaBlocks = map[string]bool{"timeouts": true}
+				for key := range resourceMetaBlocks {
+					metaBlocks[key] = true
+				}
+			}
+		}
+		v.body(page, b.Type+"."+name, b.Body, r.Schema, metaBlocks)
+	}
+}
+
+// metaBlocks is nil for nested blocks, which take no meta-arguments
+func (v *exampleValidator) body(page, path string, body *hclsyntax.Body, schemas map[string]*schema.SchemaDump, metaBlocks map[string]bool) {
+	set := make(map[string]bool)
+
+	for _, key := range sortedAttributes(body.Attributes) {
+		attr := body.Attributes[key]
+		s, ok := schemas[key]
+		switch {
+		case !ok && metaBlocks != nil && metaArguments[key]:
+		case !ok:
+			v.add(page, attr.NameRange, driftUnknownArgument, "%s.%s is not an argument", path, key)
+		case s.IsBlock():
+			v.add(page, attr.NameRange, driftBlockMismatch, "%s.%s is a block, not an attribute", path, key)
+		case !s.Optional && !s.Required:
+			v.add(page, attr.NameRange, driftComputedArgument, "%s.%s is computed and can't be set", path, key)
+		}
+		set[key] = true
+	}
+
+	for _, b := range body.Blocks {
+		key := b.Type
+		if metaBlocks[key] {
+			// dynamic "x" {} generates x blocks
+			if key != "dynamic" || len(b.Labels) != 1 {
+				continue
+			}
+			key = b.Labels[0]
+		}
+
+		s, ok := schemas[key]
+		switch {
+		case !ok:
+			v.add(page, b.TypeRange, driftUnknownArgument, "%s.%s is not an argument", path, key)
+		case s.IsBlock():
+			if key == b.Type {
+				v.body(page, path+"."+key, b.Body, s.Elem.(*schema.ResourceDump).Schema, nil)
+			}
+		// maps were commonly written as blocks up to 0.11
+		case s.Type == tfschema.TypeMap:
+		default:
+			v.add(page, b.TypeRange, driftBlockMismatch, "%s.%s is an attribute, not a block", path, key)
+		}
+		set[key] = true
+	}
+
+	for _, key := range sortedArguments(schemas, orderAlphabetical) {
+		if schemas[key].Required && !set[key] {
+			v.add(page, body.SrcRange, driftMissingArgument, "%s.%s is required", path, key)
+		}
+	}
+}
+
+func sortedAttributes(attributes hclsyntax.Attributes) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,76 @@
+package docs
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Values of -format
+const (
+	formatWebsite  = "website"
+	formatRegistry = "registry"
+	formatJSON     = "json"
+)
+
+// Where the pages of a format go under -out and how they're rendered
+type layout struct {
+	tmpl string
+	// directories under docs/ by kind of page, the provider is docs/index
+	dirs map[string]string
+	ext  string
+	// the website lists pages in <provider>.erb
+	sidebar bool
+}
+
+var layouts = map[string]*layout{
+	formatWebsite: {
+		tmpl: tmpl,
+		dirs: map[string]string{
+			kindResource:    "r",
+			kindDataSource:  "d",
+			kindProvisioner: "provisioners",
+		},
+		ext:     ".html.markdown",
+		sidebar: true,
+	},
+	formatRegistry: {
+		tmpl: registryTmpl,
+		dirs: map[string]string{
+			kindResource:    "resources",
+			kindDataSource:  "data-sources",
+			kindProvisioner: "provisioners",
+		},
+		ext: ".md",
+	},
+}
+
+func (l *layout) path(out, kind, page string) string {
+	if kind == kindProvider {
+		return filepath.Join(out, "docs", "index"+l.ext)
+	}
+	return filepath.Join(out, "docs", l.dirs[kind], page+l.ext)
+}
+
+// Subcategories are assigned by hand on the Registry, pages being rewritten
+// keep theirs
+func subcategory(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for i := 0; scanner.Scan(); i++ {
+		line := scanner.Text()
+		if i > 0 && line == "---" {
+			break
+		}
+		if strings.HasPrefix(line, "subcategory:") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "subcategory:")), `"`)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,162 @@
+package docs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/appilon/tfplugin/schema"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
+)
+
+// Helpers available to page templates, the schema ones take the Schema of
+// an ArgumentDoc or AttributeDoc
+var funcMap = template.FuncMap{
+	"required": func(s *schema.SchemaDump) bool {
+		return s != nil && s.Required
+	},
+	"forceNew": func(s *schema.SchemaDump) bool {
+		return s != nil && s.ForceNew
+	},
+	// the deprecation message, empty when not deprecated
+	"deprecated": func(s *schema.SchemaDump) string {
+		if s == nil {
+			return ""
+		}
+		return s.Deprecated
+	},
+	"sensitive": func(s *schema.SchemaDump) bool {
+		return s != nil && s.Sensitive
+	},
+	"typeName":     typeName,
+	"defaultValue": defaultValue,
+	"indent":       indent,
+}
+
+// Renders pages in one of the formats, by kind of page
+type Templates struct {
+	format string
+	pages  map[string]*template.Template
+}
+
+// Loads the built in template of format, replaced by the one at path for
+// every kind of page if set and by overrides for a single kind. Empty paths
+// are ignored. json isn't templated.
+func NewTemplates(format, path string, overrides map[string]string) (*Templates, error) {
+	t := &Templates{format: format, pages: make(map[string]*template.Template)}
+	if format == formatJSON {
+		for _, override := range overrides {
+			if override != "" {
+				path = override
+			}
+		}
+		if path != "" {
+			return nil, errors.New("templates can't be used with -format=json")
+		}
+		return t, nil
+	}
+
+	builtin, err := template.New("doc").Funcs(funcMap).Parse(layouts[format].tmpl)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if builtin, err = parseTemplate(path); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, kind := range []string{kindResource, kindDataSource, kindProvider, kindProvisioner} {
+		t.pages[kind] = builtin
+		if overrides[kind] == "" {
+			continue
+		}
+		if t.pages[kind], err = parseTemplate(overrides[kind]); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func parseTemplate(path string) (*template.Template, error) {
+	t, err := template.New(filepath.Base(path)).Funcs(funcMap).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("parsing template %s: %s", path, err)
+	}
+	return t, nil
+}
+
+func (t *Templates) Print(w io.Writer, doc *ResourceDoc) error {
+	if t.format == formatJSON {
+		return writeJSON(w, doc)
+	}
+	return t.pages[doc.Kind].Execute(w, doc)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(v)
+}
+
+// Terraform's name for the type ex: list(string), blocks are named block
+func typeName(s *schema.SchemaDump) string {
+	if s == nil {
+		return ""
+	}
+	var elem string
+	switch e := s.Elem.(type) {
+	case *schema.ResourceDump:
+		elem = "block"
+	case *schema.SchemaDump:
+		elem = typeName(e)
+	default:
+		elem = "string"
+	}
+	switch s.Type {
+	case tfschema.TypeBool:
+		return "bool"
+	case tfschema.TypeInt:
+		return "int"
+	case tfschema.TypeFloat:
+		return "float"
+	case tfschema.TypeString:
+		return "string"
+	case tfschema.TypeList:
+		return "list(" + elem + ")"
+	case tfschema.TypeSet:
+		return "set(" + elem + ")"
+	case tfschema.TypeMap:
+		return "map(" + elem + ")"
+	}
+	return ""
+}
+
+// The default as written in docs, empty when there is none
+func defaultValue(s *schema.SchemaDump) string {
+	if s == nil || s.Default == nil {
+		return ""
+	}
+	// numbers decoded from a dump are float64, %v would write 1e+06
+	if f, ok := s.Default.(float64); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", s.Default)
+}
+
+// Prefixes every non-empty line with n spaces
+func indent(n int, text string) string {
+	prefix := strings.Repeat(" ", n)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
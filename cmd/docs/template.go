@@ -1,75 +1,513 @@
 package docs
 
 import (
-	"os"
-	"text/template"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/appilon/tfplugin/schema"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
 )
 
-var tmpl = `
+const (
+	kindResource    = "resource"
+	kindDataSource  = "data source"
+	kindProvisioner = "provisioner"
+	// the provider's index page
+	kindProvider = "provider"
+)
+
+// markdown code fence, can't be written inside the raw template string
+const fence = "```"
+
+// Layout of website/docs/r/<name>.html.markdown, d/ and provisioners/ pages
+// share it with the sections that don't apply left out
+var tmpl = `---
+layout: "{{.Layout}}"
+page_title: "{{.PageTitle}}"
+sidebar_current: "{{.SidebarCurrent}}"
+description: |-
+  {{.Description}}
+---
+
+# {{.Title}}
+
+{{.Description}}
+` + pageBody("hcl", "/docs/configuration/resources.html#timeouts")
+
+// Registry pages are docs/resources/<name>.md and docs/data-sources/, the
+// Registry reads the subcategory to group them
+var registryTmpl = `---
+subcategory: "{{.Subcategory}}"
+page_title: "{{.PageTitle}}"
+description: |-
+  {{.Description}}
+---
+
+# {{if eq .Kind "resource"}}Resource: {{end}}{{.Title}}
+
+{{.Description}}
+` + pageBody("terraform", "https://www.terraform.io/docs/configuration/resources.html#timeouts")
+
+// Sections from the example on, shared by the website and the Registry
+func pageBody(language, timeoutsLink string) string {
+	return `
+## Example Usage
+
+` + fence + language + `
+{{.Example}}` + fence + `
+
 ## Argument Reference
 
 The following arguments are supported:
+{{range .Arguments}}
+* ` + "`{{.Name}}`" + ` - ({{.RequiredOrOptional}}{{with .Type}}, {{.}}{{end}}){{with .Details}} {{.}}{{end}}{{end}}
+{{- range .Blocks}}
+
+---
 
+<a id="{{.Anchor}}"></a>
+A ` + "`{{.Name}}`" + ` block supports the following:
 {{range .Arguments}}
-* {{.Name}} - ({{.RequiredOrOptional}}) {{.Description}}
-              Default: {{.Default}}
-{{end}}
+* ` + "`{{.Name}}`" + ` - ({{.RequiredOrOptional}}{{with .Type}}, {{.}}{{end}}){{with .Details}} {{.}}{{end}}{{end}}
+{{- end}}
+{{- if or .Attributes .AttributeBlocks}}
 
 ## Attributes Reference
 
-The following attributes are exported:
+{{if eq .Kind "resource"}}In addition to all arguments above, the following attributes are exported:{{else}}The following attributes are exported:{{end}}
+{{range .Attributes}}
+* ` + "`{{.Name}}`" + ` - {{.Details}}{{end}}
+{{- range .AttributeBlocks}}
+
+---
 
+<a id="{{.Anchor}}"></a>
+A ` + "`{{.Name}}`" + ` block exports the following:
 {{range .Attributes}}
-* {{.Name}} - {{.Description}}
-{{end}}
+* ` + "`{{.Name}}`" + ` - {{.Details}}{{end}}
+{{- end}}
+{{- end}}
+{{- if .Timeouts}}
+
+## Timeouts
+
+` + "`{{.Name}}`" + ` provides the following
+[Timeouts](` + timeoutsLink + `) configuration options:
+{{range .Timeouts}}
+- ` + "`{{.Name}}`" + ` - (Default ` + "`{{.Default}}`" + `) Used for {{.Operation}} the {{$.Thing}}.{{end}}
+{{- end}}
+{{- if .Import}}
+
+## Import
+
+{{.ThingTitle}} can be imported using the ` + "`id`" + `, e.g.
+
+` + fence + `
+$ terraform import {{.Name}}.example <id>
+` + fence + `
+{{- end}}
 `
+}
 
 type AttributeDoc struct {
-	Name        string
-	Description string
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Schema      *schema.SchemaDump `json:"-"`
+}
+
+// Description with the deprecation and sensitivity notes
+func (a *AttributeDoc) Details() string {
+	return joinSentences(deprecationSentence(a.Schema), a.Description, sensitiveSentence(a.Schema))
+}
+
+// Details are rendered for consumers of -format=json
+func (a *AttributeDoc) MarshalJSON() ([]byte, error) {
+	type plain AttributeDoc
+	return json.Marshal(&struct {
+		*plain
+		Details string `json:"details"`
+	}{(*plain)(a), a.Details()})
 }
 
 type ArgumentDoc struct {
-	Name               string
-	Description        string
-	RequiredOrOptional string
-	Default            interface{}
+	Name               string             `json:"name"`
+	Description        string             `json:"description"`
+	Schema             *schema.SchemaDump `json:"-"`
+	RequiredOrOptional string             `json:"required_or_optional"`
+	// Terraform's name for the type ex: list(string), empty for blocks
+	Type string `json:"type,omitempty"`
+	// formatted default, empty when there is none
+	Default       string   `json:"default,omitempty"`
+	ForceNew      bool     `json:"force_new"`
+	ConflictsWith []string `json:"conflicts_with,omitempty"`
+	// values accepted by validation.StringInSlice, only known to static dumps
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	// links nested blocks to their section, empty for plain arguments
+	Block string `json:"block,omitempty"`
 }
 
+// Description followed by the notes on the values allowed and the effects of
+// setting the argument, deprecated arguments start with a callout
+func (a *ArgumentDoc) Details() string {
+	var allowedSentence, defaultSentence, conflictsSentence, forceNewSentence string
+	if len(a.AllowedValues) > 0 {
+		allowedSentence = fmt.Sprintf("Possible values are %s.", codeList(a.AllowedValues))
+	}
+	if a.Default != "" {
+		defaultSentence = fmt.Sprintf("Defaults to `%s`.", a.Default)
+	}
+	if len(a.ConflictsWith) > 0 {
+		conflictsSentence = fmt.Sprintf("Conflicts with %s.", codeList(a.ConflictsWith))
+	}
+	if a.ForceNew {
+		forceNewSentence = "Changing this forces a new resource to be created."
+	}
+	return joinSentences(deprecationSentence(a.Schema), a.Description, a.Block, allowedSentence, defaultSentence,
+		conflictsSentence, sensitiveSentence(a.Schema), forceNewSentence)
+}
+
+func (a *ArgumentDoc) MarshalJSON() ([]byte, error) {
+	type plain ArgumentDoc
+	return json.Marshal(&struct {
+		*plain
+		Details string `json:"details"`
+	}{(*plain)(a), a.Details()})
+}
+
+// Section documenting the children of a nested block
+type BlockDoc struct {
+	Name       string          `json:"name"`
+	Anchor     string          `json:"anchor"`
+	Arguments  []*ArgumentDoc  `json:"arguments"`
+	Attributes []*AttributeDoc `json:"attributes"`
+}
+
+type TimeoutDoc struct {
+	Name      string `json:"name"`
+	Default   string `json:"default"`
+	Operation string `json:"operation"`
+}
+
+// Data a page template is executed with, and what -format=json writes
 type ResourceDoc struct {
-	Arguments  []*ArgumentDoc
-	Attributes []*AttributeDoc
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// the dump being documented, a provider or provisioner has only Schema
+	Resource *schema.ResourceDump `json:"-"`
+	// front matter, Subcategory is only written to registry pages
+	Layout         string `json:"layout"`
+	PageTitle      string `json:"page_title"`
+	SidebarCurrent string `json:"sidebar_current"`
+	Subcategory    string `json:"subcategory"`
+	Description    string `json:"description"`
+
+	Title string `json:"title"`
+	// what the resource manages ex: hook for netlify_hook
+	Thing      string          `json:"thing"`
+	ThingTitle string          `json:"thing_title"`
+	Example    string          `json:"example"`
+	Arguments  []*ArgumentDoc  `json:"arguments"`
+	Attributes []*AttributeDoc `json:"attributes"`
+	Timeouts   []*TimeoutDoc   `json:"timeouts"`
+	Import     bool            `json:"import"`
+
+	// nested blocks in depth first order, the ones only exported as
+	// attributes are documented separately
+	Blocks          []*BlockDoc `json:"blocks"`
+	AttributeBlocks []*BlockDoc `json:"attribute_blocks"`
+
+	order string
 }
 
-func PrintDoc(resource *schema.ResourceDump) error {
-	t, err := template.New("doc").Parse(tmpl)
-	if err != nil {
-		return err
+// Builds the page for a resource, data source, provisioner or the provider
+// index, the provider is taken from the name of resources and data sources.
+// Arguments and attributes are sorted in order.
+func NewResourceDoc(kind, name string, resource *schema.ResourceDump, order string) *ResourceDoc {
+	provider := strings.SplitN(name, "_", 2)[0]
+	thing := strings.Replace(strings.TrimPrefix(name, provider+"_"), "_", " ", -1)
+	providerTitle := strings.Title(provider)
+
+	d := &ResourceDoc{
+		Kind:       kind,
+		Name:       name,
+		Resource:   resource,
+		Thing:      thing,
+		order:      order,
+		ThingTitle: strings.Title(thing) + "s",
 	}
 
-	data := &ResourceDoc{}
-	for name, s := range resource.Schema {
-		if s.Computed {
-			data.Attributes = append(data.Attributes, &AttributeDoc{
-				Name:        name,
-				Description: s.Description,
-			})
-		} else {
-			reqOrOpt := "Required"
-			if s.Optional {
-				reqOrOpt = "Optional"
+	switch kind {
+	case kindResource:
+		d.Layout = provider
+		d.PageTitle = fmt.Sprintf("%s: %s", providerTitle, name)
+		d.SidebarCurrent = fmt.Sprintf("docs-%s-resource-%s", provider, sidebarName(name, provider))
+		d.Description = fmt.Sprintf("Provides a %s %s resource.", providerTitle, thing)
+		d.Title = name
+		d.Import = resource.Importer != nil
+		d.Attributes = append(d.Attributes, &AttributeDoc{
+			Name:        "id",
+			Description: fmt.Sprintf("The ID of the %s.", thing),
+		})
+	case kindDataSource:
+		d.Layout = provider
+		d.PageTitle = fmt.Sprintf("%s: %s", providerTitle, name)
+		d.SidebarCurrent = fmt.Sprintf("docs-%s-datasource-%s", provider, sidebarName(name, provider))
+		d.Description = fmt.Sprintf("Use this data source to get information about a %s %s.", providerTitle, thing)
+		d.Title = "Data Source: " + name
+	case kindProvider:
+		d.Layout = provider
+		d.PageTitle = "Provider: " + providerTitle
+		d.SidebarCurrent = fmt.Sprintf("docs-%s-index", provider)
+		d.Description = fmt.Sprintf("The %s provider is used to interact with the resources supported by %s.", providerTitle, providerTitle)
+		d.Title = providerTitle + " Provider"
+		d.Thing = provider
+	case kindProvisioner:
+		d.Layout = "docs"
+		d.PageTitle = "Provisioner: " + name
+		d.SidebarCurrent = "docs-provisioners-" + name
+		d.Description = fmt.Sprintf("The %s provisioner.", name)
+		d.Title = name + " Provisioner"
+		d.Thing = name
+	}
+
+	d.Example = exampleConfig(kind, name, resource)
+
+	arguments, attributes := d.document(resource.Schema, nil)
+	d.Arguments = arguments
+	d.Attributes = append(d.Attributes, attributes...)
+
+	if t := resource.Timeouts; t != nil {
+		for _, timeout := range []struct {
+			name      string
+			operation string
+			value     *time.Duration
+		}{
+			{tfschema.TimeoutCreate, "creating", t.Create},
+			{tfschema.TimeoutRead, "reading", t.Read},
+			{tfschema.TimeoutUpdate, "updating", t.Update},
+			{tfschema.TimeoutDelete, "deleting", t.Delete},
+			{tfschema.TimeoutDefault, "any operation on", t.Default},
+		} {
+			if timeout.value == nil {
+				continue
 			}
-			data.Arguments = append(data.Arguments, &ArgumentDoc{
-				Name:               name,
-				Description:        s.Description,
-				RequiredOrOptional: reqOrOpt,
-				Default:            s.Default,
+			d.Timeouts = append(d.Timeouts, &TimeoutDoc{
+				Name:      timeout.name,
+				Default:   humanDuration(*timeout.value),
+				Operation: timeout.operation,
 			})
 		}
 	}
 
-	t.Execute(os.Stdout, data)
-	return nil
+	return d
+}
+
+// Documents the schemas of a resource or nested block at path, blocks
+// found along the way get their own section
+func (d *ResourceDoc) document(schemas map[string]*schema.SchemaDump, path []string) ([]*ArgumentDoc, []*AttributeDoc) {
+	var arguments []*ArgumentDoc
+	var attributes []*AttributeDoc
+	for _, key := range sortedArguments(schemas, d.order) {
+		s := schemas[key]
+		if s.Computed && !s.Optional && !s.Required {
+			attr := &AttributeDoc{
+				Name:        key,
+				Description: s.Description,
+				Schema:      s,
+			}
+			if s.IsBlock() {
+				block := d.attributeBlock(key, s.Elem.(*schema.ResourceDump).Schema, append(path, key))
+				attr.Description = joinSentences(attr.Description, blockSentence(key, block.Anchor, s))
+			}
+			attributes = append(attributes, attr)
+			continue
+		}
+
+		reqOrOpt := "Required"
+		if s.Optional {
+			reqOrOpt = "Optional"
+		}
+		arg := &ArgumentDoc{
+			Name:               key,
+			Description:        s.Description,
+			Schema:             s,
+			RequiredOrOptional: reqOrOpt,
+			ForceNew:           s.ForceNew && d.Kind == kindResource,
+		}
+		arg.Default = defaultValue(s)
+		arg.ConflictsWith = s.ConflictsWith
+		if s.ValidateFunc != nil {
+			arg.AllowedValues = s.ValidateFunc.AllowedValues
+		}
+		if !s.IsBlock() {
+			arg.Type = typeName(s)
+		}
+		if s.IsBlock() {
+			blockPath := append(append([]string{}, path...), key)
+			block := &BlockDoc{
+				Name:   key,
+				Anchor: strings.Join(blockPath, "-"),
+			}
+			// appended before recursing so parents come first
+			d.Blocks = append(d.Blocks, block)
+			block.Arguments, block.Attributes = d.document(s.Elem.(*schema.ResourceDump).Schema, blockPath)
+			arg.Block = blockSentence(key, block.Anchor, s)
+			if len(block.Attributes) > 0 {
+				// computed children of an argument block are exported with it
+				exported := &BlockDoc{Name: key, Anchor: block.Anchor + "-attributes", Attributes: block.Attributes}
+				d.AttributeBlocks = append(d.AttributeBlocks, exported)
+			}
+		}
+		arguments = append(arguments, arg)
+	}
+	return arguments, attributes
+}
+
+// Blocks only exported as attributes, everything within is an attribute
+func (d *ResourceDoc) attributeBlock(name string, schemas map[string]*schema.SchemaDump, path []string) *BlockDoc {
+	blockPath := append([]string{}, path...)
+	block := &BlockDoc{
+		Name:   name,
+		Anchor: strings.Join(blockPath, "-"),
+	}
+	d.AttributeBlocks = append(d.AttributeBlocks, block)
+	for _, key := range sortedArguments(schemas, d.order) {
+		s := schemas[key]
+		attr := &AttributeDoc{
+			Name:        key,
+			Description: s.Description,
+			Schema:      s,
+		}
+		if s.IsBlock() {
+			nested := d.attributeBlock(key, s.Elem.(*schema.ResourceDump).Schema, append(blockPath, key))
+			attr.Description = joinSentences(attr.Description, blockSentence(key, nested.Anchor, s))
+		}
+		block.Attributes = append(block.Attributes, attr)
+	}
+	return block
+}
+
+// How many blocks are allowed according to MinItems and MaxItems, linked
+// to the block's section
+func blockSentence(name, anchor string, s *schema.SchemaDump) string {
+	link := fmt.Sprintf("[`%s`](#%s)", name, anchor)
+	switch {
+	case s.MaxItems == 1 && (s.Required || s.MinItems == 1):
+		return fmt.Sprintf("A %s block as defined below.", link)
+	case s.MaxItems == 1:
+		return fmt.Sprintf("At most one %s block as defined below.", link)
+	case s.MinItems > 1 && s.MaxItems > 1:
+		return fmt.Sprintf("Between %d and %d %s blocks as defined below.", s.MinItems, s.MaxItems, link)
+	case s.MinItems > 1:
+		return fmt.Sprintf("At least %d %s blocks as defined below.", s.MinItems, link)
+	case s.MaxItems > 1:
+		return fmt.Sprintf("Up to %d %s blocks as defined below.", s.MaxItems, link)
+	case s.Required || s.MinItems == 1:
+		return fmt.Sprintf("One or more %s blocks as defined below.", link)
+	}
+	return fmt.Sprintf("Zero or more %s blocks as defined below.", link)
+}
+
+func deprecationSentence(s *schema.SchemaDump) string {
+	switch {
+	case s == nil:
+	case s.Removed != "":
+		return "**Removed:** " + s.Removed
+	case s.Deprecated != "":
+		return "**Deprecated:** " + s.Deprecated
+	}
+	return ""
+}
+
+func sensitiveSentence(s *schema.SchemaDump) string {
+	if s == nil || !s.Sensitive {
+		return ""
+	}
+	return "This value is sensitive and will not be displayed in the plan."
+}
+
+// `a`, `b` and `c`
+func codeList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "`" + v + "`"
+	}
+	if len(quoted) == 1 {
+		return quoted[0]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + " and " + quoted[len(quoted)-1]
+}
+
+func joinSentences(sentences ...string) string {
+	var parts []string
+	for _, s := range sentences {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// netlify_build_hook becomes build-hook
+func sidebarName(name, provider string) string {
+	return strings.Replace(strings.TrimPrefix(name, provider+"_"), "_", "-", -1)
+}
+
+// Orders of arguments and attributes on a page, values of -sort
+const (
+	orderAlphabetical  = "alphabetical"
+	orderRequiredFirst = "required-first"
+	// where they're declared, only known to static dumps, the others
+	// follow alphabetically
+	orderSource = "source"
+)
+
+func sortedArguments(schemas map[string]*schema.SchemaDump, order string) []string {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := schemas[keys[i]], schemas[keys[j]]
+		switch order {
+		case orderRequiredFirst:
+			if a.Required != b.Required {
+				return a.Required
+			}
+		case orderSource:
+			if (a.Position == nil) != (b.Position == nil) {
+				return a.Position != nil
+			}
+			if a.Position != nil && *a.Position != *b.Position {
+				if a.Position.Filename != b.Position.Filename {
+					return a.Position.Filename < b.Position.Filename
+				}
+				return a.Position.Line < b.Position.Line
+			}
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// 10m0s becomes 10 minutes
+func humanDuration(d time.Duration) string {
+	unit := func(n int64, name string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, name)
+		}
+		return fmt.Sprintf("%d %ss", n, name)
+	}
+	switch {
+	case d >= time.Hour && d%time.Hour == 0:
+		return unit(int64(d/time.Hour), "hour")
+	case d >= time.Minute && d%time.Minute == 0:
+		return unit(int64(d/time.Minute), "minute")
+	case d%time.Second == 0:
+		return unit(int64(d/time.Second), "second")
+	}
+	return d.String()
 }
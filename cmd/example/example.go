@@ -0,0 +1,88 @@
+package example
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/appilon/tfplugin/cmd/docs"
+	"github.com/appilon/tfplugin/schema"
+	"github.com/appilon/tfplugin/util"
+	"github.com/mitchellh/cli"
+)
+
+const CommandName = "example"
+
+type command struct{}
+
+func (c *command) Help() string {
+	return ""
+}
+
+func (c *command) Synopsis() string {
+	return ""
+}
+
+func CommandFactory() (cli.Command, error) {
+	return &command{}, nil
+}
+
+func (c *command) Run(args []string) int {
+	flags := flag.NewFlagSet(CommandName, flag.ExitOnError)
+	var datasource string
+	var resource string
+	var provider bool
+	var opts schema.ExampleOptions
+	flags.StringVar(&datasource, "datasource", "", "data source name")
+	flags.StringVar(&resource, "resource", "", "resource name")
+	flags.BoolVar(&provider, "provider", false, "configure the provider itself")
+	flags.BoolVar(&opts.Optional, "optional", false, "set optional arguments as well")
+	flags.StringVar(&opts.Syntax, "syntax", schema.Syntax012, "configuration language version, 0.11 or 0.12")
+	flags.Parse(args)
+
+	if opts.Syntax != schema.Syntax011 && opts.Syntax != schema.Syntax012 {
+		log.Printf("Error unknown syntax %q, must be %s or %s", opts.Syntax, schema.Syntax011, schema.Syntax012)
+		return 1
+	}
+
+	envelope, err := schema.ReadProviderDump(os.Stdin)
+	if err != nil {
+		log.Printf("Error decoding provider json: %s", err)
+		return 1
+	}
+
+	if envelope.Provisioner != nil {
+		name, err := util.GetPackageName(envelope.Provider)
+		if err != nil {
+			log.Printf("Error determining provisioner name: %s", err)
+			return 1
+		}
+		fmt.Print(schema.ExampleProvisioner(name, envelope.Provisioner.Schema, opts))
+		return 0
+	}
+	p := envelope.Schema
+
+	switch {
+	case datasource != "":
+		r, exists := p.DataSourcesMap[datasource]
+		if !exists {
+			log.Printf("Error '%s' is not a data source", datasource)
+			return 1
+		}
+		fmt.Print(schema.ExampleDataSource(datasource, r, opts))
+	case resource != "":
+		r, exists := p.ResourcesMap[resource]
+		if !exists {
+			log.Printf("Error '%s' is not a resource", resource)
+			return 1
+		}
+		fmt.Print(schema.ExampleResource(resource, r, opts))
+	case provider:
+		fmt.Print(schema.ExampleProvider(docs.ProviderName(envelope), p.Schema, opts))
+	default:
+		return cli.RunResultHelp
+	}
+
+	return 0
+}
@@ -0,0 +1,78 @@
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/appilon/tfplugin/schema/convert"
+	"github.com/appilon/tfplugin/util"
+	hclog "github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/terraform/plugin"
+	"github.com/hashicorp/terraform/plugin/discovery"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// Launches a compiled provider and requests its schema over the plugin protocol
+func dumpFromBinary(binaryPath string) (*schema.ProviderDump, error) {
+	fullPath, err := filepath.Abs(binaryPath)
+	if err != nil {
+		return nil, err
+	}
+
+	config := plugin.ClientConfig(discovery.PluginMeta{Path: fullPath})
+	// the client is killed when done, no need for plugin.CleanupClients
+	config.Managed = false
+	// go-plugin logs its own shutdown, such as reading stderr of the killed
+	// provider, errors that matter are returned
+	config.Logger = hclog.New(&hclog.LoggerOptions{
+		Name:   "plugin",
+		Output: ioutil.Discard,
+	})
+	client := goplugin.NewClient(config)
+	defer client.Kill()
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		return nil, fmt.Errorf("Error starting %s: %s", fullPath, err)
+	}
+
+	raw, err := rpcClient.Dispense(plugin.ProviderPluginName)
+	if err != nil {
+		return nil, fmt.Errorf("Error dispensing provider: %s", err)
+	}
+
+	provider, ok := raw.(terraform.ResourceProvider)
+	if !ok {
+		return nil, errors.New("plugin does not implement terraform.ResourceProvider")
+	}
+
+	// the schema of every resource and data source needs to be asked for by name
+	req := &terraform.ProviderSchemaRequest{}
+	for _, r := range provider.Resources() {
+		req.ResourceTypes = append(req.ResourceTypes, r.Name)
+	}
+	for _, d := range provider.DataSources() {
+		req.DataSources = append(req.DataSources, d.Name)
+	}
+
+	s, err := provider.GetSchema(req)
+	if err != nil {
+		return nil, fmt.Errorf("Error getting schema: %s", err)
+	}
+
+	return convert.NewProviderDump(s), nil
+}
+
+// Provider name from a binary such as terraform-provider-netlify_v0.1.0
+func binaryProviderName(binaryPath string) (string, error) {
+	name, err := util.GetPackageName(filepath.Base(binaryPath))
+	if err != nil {
+		return "", err
+	}
+	return strings.SplitN(name, "_", 2)[0], nil
+}
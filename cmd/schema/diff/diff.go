@@ -0,0 +1,112 @@
+package diff
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/mitchellh/cli"
+)
+
+const CommandName = "schema diff"
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// Exit status when breaking changes were found, errors exit with 1
+const exitBreaking = 2
+
+type command struct{}
+
+func (c *command) Help() string {
+	return ""
+}
+
+func (c *command) Synopsis() string {
+	return ""
+}
+
+func CommandFactory() (cli.Command, error) {
+	return &command{}, nil
+}
+
+func (c *command) Run(args []string) int {
+	flags := flag.NewFlagSet(CommandName, flag.ExitOnError)
+	var format string
+	flags.StringVar(&format, "format", formatText, "output format, text or json")
+	flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		return cli.RunResultHelp
+	}
+
+	old, err := readDump(flags.Arg(0))
+	if err != nil {
+		log.Printf("Error reading %s: %s", flags.Arg(0), err)
+		return 1
+	}
+	new, err := readDump(flags.Arg(1))
+	if err != nil {
+		log.Printf("Error reading %s: %s", flags.Arg(1), err)
+		return 1
+	}
+
+	var changes []*schema.Change
+	switch {
+	case old.Schema != nil && new.Schema != nil:
+		changes = schema.DiffProviders(old.Schema, new.Schema)
+	case old.Provisioner != nil && new.Provisioner != nil:
+		changes = schema.DiffProvisioners(old.Provisioner, new.Provisioner)
+	default:
+		log.Printf("Error comparing %s and %s: a provider can't be compared with a provisioner", flags.Arg(0), flags.Arg(1))
+		return 1
+	}
+
+	switch format {
+	case formatText:
+		printText(changes)
+	case formatJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		if changes == nil {
+			changes = []*schema.Change{}
+		}
+		if err := enc.Encode(changes); err != nil {
+			log.Printf("Error writing changes: %s", err)
+			return 1
+		}
+	default:
+		log.Printf("Unknown format %q", format)
+		return 1
+	}
+
+	if schema.HasBreakingChanges(changes) {
+		return exitBreaking
+	}
+	return 0
+}
+
+func readDump(path string) (*schema.DumpEnvelope, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return schema.ReadProviderDump(f)
+}
+
+func printText(changes []*schema.Change) {
+	counts := make(map[schema.Severity]int)
+	for _, c := range changes {
+		fmt.Println(c)
+		counts[c.Severity]++
+	}
+	fmt.Printf("%d breaking, %d state migration, %d non-breaking changes\n",
+		counts[schema.SeverityBreaking], counts[schema.SeverityStateMigration], counts[schema.SeverityNonBreaking])
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/mitchellh/cli"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfplugin-diff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	attribute := func(required bool) map[string]*schema.SchemaDump {
+		return map[string]*schema.SchemaDump{
+			"name": {Optional: !required, Required: required},
+		}
+	}
+	dumps := map[string]*schema.DumpEnvelope{
+		"optional.json": {Schema: &schema.ProviderDump{ResourcesMap: map[string]*schema.ResourceDump{
+			"x_thing": {Schema: attribute(false)},
+		}}},
+		"required.json": {Schema: &schema.ProviderDump{ResourcesMap: map[string]*schema.ResourceDump{
+			"x_thing": {Schema: attribute(true)},
+		}}},
+		"provisioner.json": {Provisioner: &schema.ProvisionerDump{Schema: attribute(false)}},
+	}
+	for name, dump := range dumps {
+		e := schema.NewDumpEnvelope("github.com/terraform-providers/terraform-provider-x", time.Now())
+		e.Schema, e.Provisioner = dump.Schema, dump.Provisioner
+		f, err := os.Create(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := e.ToJSON(f); err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+	}
+	// a dump from before the envelope
+	legacy, err := os.Create(filepath.Join(dir, "legacy.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dumps["required.json"].Schema.ToJSON(legacy); err != nil {
+		t.Fatal(err)
+	}
+	legacy.Close()
+
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{"no changes", []string{"optional.json", "optional.json"}, 0},
+		{"non-breaking", []string{"required.json", "optional.json"}, 0},
+		{"breaking", []string{"optional.json", "required.json"}, exitBreaking},
+		{"breaking json", []string{"-format", "json", "optional.json", "required.json"}, exitBreaking},
+		{"without envelope", []string{"optional.json", "legacy.json"}, exitBreaking},
+		{"provider and provisioner", []string{"optional.json", "provisioner.json"}, 1},
+		{"missing dump", []string{"optional.json", "missing.json"}, 1},
+		{"unknown format", []string{"-format", "yaml", "optional.json", "required.json"}, 1},
+		{"one dump", []string{"optional.json"}, cli.RunResultHelp},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := make([]string, len(c.args))
+			for i, arg := range c.args {
+				if filepath.Ext(arg) == ".json" {
+					arg = filepath.Join(dir, arg)
+				}
+				args[i] = arg
+			}
+			if code := (&command{}).Run(args); code != c.code {
+				t.Errorf("Run(%q) = %d, want %d", c.args, code, c.code)
+			}
+		})
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"log"
 	"os"
 	"github.com/appilon/tfplugin/schema"
-	p "%s/%s"
+	p "%s"
 )
 
 func main() {
-	if err := schema.NewProviderDump(p.Provider).ToJSON(os.Stdout); err != nil {
+	if err := schema.%s; err != nil {
 		log.Fatal(err)
 	}
 }
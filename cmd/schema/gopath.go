@@ -0,0 +1,106 @@
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/appilon/tfplugin/util"
+)
+
+// Sets up a GOPATH in dir that mirrors the provider with symlinks. The schema
+// package is copied into the mirror's vendor/ so it is built against the
+// provider's vendored SDK, the provider tree is only ever read.
+func newGopathDumper(dir, providerPath string) (*dumperBuild, error) {
+	importPath, err := util.GopathImportPath(providerPath)
+	if err != nil {
+		return nil, err
+	}
+
+	source, err := util.FindInGopath(tfpluginModule)
+	if err != nil {
+		return nil, err
+	}
+
+	mirrorPath := filepath.Join(dir, "src", filepath.FromSlash(importPath))
+	vendoredSchema := filepath.ToSlash(filepath.Join("vendor", tfpluginModule, "schema"))
+	if err := mirror(providerPath, mirrorPath, vendoredSchema); err != nil {
+		return nil, err
+	}
+
+	if err := copyGoFiles(filepath.Join(source, "schema"), filepath.Join(mirrorPath, filepath.FromSlash(vendoredSchema))); err != nil {
+		return nil, err
+	}
+
+	dumperPath := filepath.Join(mirrorPath, "tfplugin-dumper")
+	if err := os.Mkdir(dumperPath, 0755); err != nil {
+		return nil, err
+	}
+
+	return &dumperBuild{
+		dir:        dumperPath,
+		importPath: importPath,
+		env:        append(os.Environ(), "GO111MODULE=off", "GOPATH="+dir+string(filepath.ListSeparator)+os.Getenv("GOPATH")),
+	}, nil
+}
+
+// Mirrors src into dst with symlinks, the directories along keep are
+// created for real so files can be added to them
+func mirror(src, dst, keep string) error {
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+
+	entries, err := ioutil.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	segments := strings.SplitN(keep, "/", 2)
+	for _, entry := range entries {
+		from := filepath.Join(src, entry.Name())
+		to := filepath.Join(dst, entry.Name())
+		if entry.Name() == segments[0] && entry.IsDir() {
+			var rest string
+			if len(segments) > 1 {
+				rest = segments[1]
+			}
+			if err := mirror(from, to, rest); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.Symlink(from, to); err != nil {
+			return err
+		}
+	}
+
+	// keep may not exist in src at all
+	if keep != "" {
+		return os.MkdirAll(filepath.Join(dst, filepath.FromSlash(keep)), 0755)
+	}
+	return nil
+}
+
+func copyGoFiles(src, dst string) error {
+	files, err := filepath.Glob(filepath.Join(src, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(filepath.Join(dst, filepath.Base(file)), content, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package schema
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"github.com/appilon/tfplugin/util"
+	"github.com/radeksimko/go-mod-diff/go-src/cmd/go/_internal/modfile"
+)
+
+const (
+	tfpluginModule = "github.com/appilon/tfplugin"
+	sdkModule      = "github.com/hashicorp/terraform"
+)
+
+// Sets up a module in dir that requires the provider through a replace
+// directive, the provider tree is only ever read
+func newModuleDumper(dir, providerPath string) (*dumperBuild, error) {
+	provider, err := util.ReadGoMod(providerPath)
+	if err != nil {
+		return nil, err
+	}
+	if provider.Module == nil {
+		return nil, errors.New("go.mod has no module statement")
+	}
+	modulePath := provider.Module.Mod.Path
+
+	f, err := modfile.Parse("go.mod", []byte("module tfplugin-dumper\n"), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	f.AddNewRequire(modulePath, "v0.0.0", false)
+	f.AddReplace(modulePath, "", providerPath, "")
+
+	version, source, err := tfpluginSource()
+	if err != nil {
+		return nil, err
+	}
+	if source != "" {
+		f.AddNewRequire(tfpluginModule, "v0.0.0", false)
+		f.AddReplace(tfpluginModule, "", source, "")
+	} else {
+		f.AddNewRequire(tfpluginModule, version, false)
+	}
+
+	// the schema package has to be built against the provider's SDK,
+	// left to MVS tfplugin's own requirement would win if newer. A provider
+	// replacing the SDK itself already pins it below.
+	replacesSDK := false
+	for _, r := range provider.Replace {
+		if r.Old.Path == sdkModule {
+			replacesSDK = true
+			break
+		}
+	}
+	for _, r := range provider.Require {
+		if r.Mod.Path == sdkModule && !replacesSDK {
+			f.AddReplace(sdkModule, "", sdkModule, r.Mod.Version)
+			break
+		}
+	}
+
+	// replace directives only apply to the main module, carry the provider's over
+	for _, r := range provider.Replace {
+		newPath := r.New.Path
+		if modfile.IsDirectoryPath(newPath) && !filepath.IsAbs(newPath) {
+			newPath = filepath.Join(providerPath, newPath)
+		}
+		f.AddReplace(r.Old.Path, r.Old.Version, newPath, r.New.Version)
+	}
+
+	content, err := f.Format()
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "go.mod"), content, 0644); err != nil {
+		return nil, err
+	}
+
+	// start from the provider's checksums, go mod tidy fills in the rest
+	if sum, err := ioutil.ReadFile(filepath.Join(providerPath, "go.sum")); err == nil {
+		if err := ioutil.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return &dumperBuild{
+		dir:        dir,
+		importPath: modulePath,
+		modules:    true,
+		// GOFLAGS commonly carries -mod=vendor for providers, there is no vendor/ here
+		env: append(os.Environ(), "GO111MODULE=on", "GOFLAGS="),
+	}, nil
+}
+
+// The dumper is built against the same tfplugin as the one running, either
+// a released version or the source found on GOPATH
+func tfpluginSource() (version string, source string, err error) {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Path == tfpluginModule {
+		v := info.Main.Version
+		if v != "" && v != "(devel)" && !strings.HasSuffix(v, "+dirty") {
+			return v, "", nil
+		}
+	}
+
+	source, err = util.FindInGopath(tfpluginModule)
+	return "", source, err
+}
@@ -1,17 +1,30 @@
 package schema
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
+	"github.com/appilon/tfplugin/schema"
+	"github.com/appilon/tfplugin/schema/convert"
+	"github.com/appilon/tfplugin/schema/static"
 	"github.com/appilon/tfplugin/util"
 	"github.com/mitchellh/cli"
 )
 
 const CommandName = "schema"
 
+const (
+	formatLegacy        = "legacy"
+	formatTerraformJSON = "terraform-json"
+)
+
 type command struct{}
 
 func (c *command) Help() string {
@@ -26,84 +39,268 @@ func CommandFactory() (cli.Command, error) {
 	return &command{}, nil
 }
 
-func moveVendoredTerraform(path string, undo bool) (bool, error) {
-	oldPath := filepath.Join(path, "vendor/github.com/hashicorp/terraform")
-	newPath := oldPath
-	if undo {
-		oldPath += "x"
-	} else {
-		newPath += "x"
-	}
+// Where and how the dumper is built
+type dumperBuild struct {
+	dir        string
+	env        []string
+	importPath string
+	modules    bool
+}
 
-	if _, err := os.Stat(oldPath); os.IsNotExist(err) {
-		return false, nil
+func (c *command) Run(args []string) int {
+	flags := flag.NewFlagSet(CommandName, flag.ExitOnError)
+	var format string
+	var binary string
+	var static bool
+	var validate bool
+	flags.StringVar(&format, "format", formatLegacy, "output format, legacy or terraform-json")
+	flags.StringVar(&binary, "binary", "", "compiled provider to extract the schema from over the plugin protocol, without descriptions, defaults, ForceNew or functions")
+	flags.BoolVar(&static, "static", false, "extract the schema from the provider source without compiling it")
+	flags.BoolVar(&validate, "validate", false, "report schema problems instead of printing the schema")
+	flags.Parse(args)
+
+	if format != formatLegacy && format != formatTerraformJSON {
+		log.Printf("Unknown format %q", format)
+		return 1
 	}
 
-	if err := os.Rename(oldPath, newPath); err != nil {
-		return false, err
+	if binary != "" {
+		return runBinary(binary, format, validate)
 	}
-	return true, nil
-}
 
-func (c *command) Run(args []string) int {
-	var providerPath string
-	if len(args) > 0 {
-		providerPath = args[0]
+	if static {
+		return runStatic(flags.Arg(0), format, validate)
 	}
-	fullPath, err := util.FindProvider(providerPath)
+
+	fullPath, err := util.FindProvider(flags.Arg(0))
 	if err != nil {
 		log.Printf("Error finding provider: %s", err)
 		return 1
 	}
 
-	packageName, err := util.GetPackageName(fullPath)
+	dir, err := ioutil.TempDir("", "tfplugin-schema")
+	if err != nil {
+		log.Printf("Error creating temporary directory: %s", err)
+		return 1
+	}
+	defer os.RemoveAll(dir)
+
+	// the dumper is built outside of the provider, nothing is written into it
+	var build *dumperBuild
+	if _, err = os.Stat(filepath.Join(fullPath, "go.mod")); err == nil {
+		build, err = newModuleDumper(dir, fullPath)
+	} else if os.IsNotExist(err) {
+		build, err = newGopathDumper(dir, fullPath)
+	}
+	if err != nil {
+		log.Printf("Error setting up dumper: %s", err)
+		return 1
+	}
+
+	packageName, err := util.GetPackageName(build.importPath)
 	if err != nil {
 		log.Printf("Error determining package exporting provider: %s", err)
 		return 1
 	}
 
-	file, err := os.Create(filepath.Join(fullPath, "dumper.go"))
+	provisioner := util.IsProvisioner(build.importPath)
+
+	// the dump is converted and wrapped here, the same as the other modes.
+	// InternalValidate needs the plugin itself, with -validate the dumper reports
+	var encode string
+	switch {
+	case provisioner && validate:
+		encode = "NewProvisionerValidationReport(p.Provisioner).ToJSON(os.Stdout)"
+	case provisioner:
+		encode = "NewProvisionerDump(p.Provisioner).ToJSON(os.Stdout)"
+	case validate:
+		encode = "NewValidationReport(p.Provider).ToJSON(os.Stdout)"
+	default:
+		encode = "NewProviderDump(p.Provider).ToJSON(os.Stdout)"
+	}
+
+	content := fmt.Sprintf(dumper, build.importPath+"/"+packageName, encode)
+	if err = ioutil.WriteFile(filepath.Join(build.dir, "main.go"), []byte(content), 0644); err != nil {
+		log.Printf("Could not write dumper: %s", err)
+		return 1
+	}
+
+	if build.modules {
+		if err = util.Run(build.env, build.dir, "go", "mod", "tidy"); err != nil {
+			log.Printf("go mod tidy exited with error: %s", err)
+			return 1
+		}
+	}
+
+	output, err := util.Output(build.env, build.dir, "go", "run", ".")
+	if err != nil {
+		log.Printf("go run exited with error: %s", err)
+		return 1
+	}
+
+	if validate {
+		report := &schema.ValidationReport{}
+		if err := json.Unmarshal(output, report); err != nil {
+			log.Printf("Error decoding validation report: %s", err)
+			return 1
+		}
+		return writeReport(report)
+	}
+
+	envelope := newEnvelope(build.importPath, fullPath)
+	if provisioner {
+		envelope.Provisioner = &schema.ProvisionerDump{}
+		err = json.Unmarshal(output, envelope.Provisioner)
+	} else {
+		envelope.Schema = &schema.ProviderDump{}
+		err = json.Unmarshal(output, envelope.Schema)
+	}
+	if err != nil {
+		log.Printf("Error decoding dump: %s", err)
+		return 1
+	}
+
+	return writeDump(envelope, format, packageName)
+}
+
+func runBinary(binary, format string, validate bool) int {
+	if util.IsProvisioner(filepath.Base(binary)) {
+		log.Printf("Error extracting schema from %s: provisioners don't serve their schema over the plugin protocol", binary)
+		return 1
+	}
+
+	name, err := binaryProviderName(binary)
+	if err != nil {
+		log.Printf("Error determining provider name: %s", err)
+		return 1
+	}
+
+	dump, err := dumpFromBinary(binary)
+	if err != nil {
+		log.Printf("Error extracting schema from %s: %s", binary, err)
+		return 1
+	}
+
+	if validate {
+		return writeReport(&schema.ValidationReport{Findings: schema.LintProvider(dump, schema.LintOptions{})})
+	}
+	envelope := newEnvelope(filepath.Base(binary), "")
+	envelope.Schema = dump
+	return writeDump(envelope, format, name)
+}
+
+func runStatic(providerPath, format string, validate bool) int {
+	fullPath, err := util.FindProvider(providerPath)
 	if err != nil {
-		log.Printf("Cannot create dumper.go: %s", err)
+		log.Printf("Error finding provider: %s", err)
 		return 1
 	}
 
-	if _, err = fmt.Fprintf(file, dumper, providerPath, packageName); err != nil {
-		log.Printf("Could not write to dumper.go: %s", err)
+	importPath, err := util.ImportPath(fullPath)
+	if err != nil {
+		log.Printf("Error determining import path of provider: %s", err)
+		return 1
+	}
+
+	packageName, err := util.GetPackageName(importPath)
+	if err != nil {
+		log.Printf("Error determining package exporting provider: %s", err)
 		return 1
 	}
 
-	// sigh, golang vendoring woes
-	moved, err := moveVendoredTerraform(fullPath, false)
+	var provider *schema.ProviderDump
+	var provisioner *schema.ProvisionerDump
+	if util.IsProvisioner(importPath) {
+		provisioner, err = static.ExtractProvisioner(fullPath, importPath, packageName)
+	} else {
+		provider, err = static.Extract(fullPath, importPath, packageName)
+	}
 	if err != nil {
-		log.Printf("Error moving vendored terraform: %s", err)
+		log.Printf("Error extracting schema from source: %s", err)
 		return 1
 	}
 
-	// going forward errors don't exit right away, attempt cleanup
-	var status int
+	if validate && provisioner != nil {
+		return writeReport(&schema.ValidationReport{Findings: schema.LintProvisioner(provisioner, schema.LintOptions{Descriptions: true})})
+	} else if validate {
+		return writeReport(&schema.ValidationReport{Findings: schema.LintProvider(provider, schema.LintOptions{Descriptions: true})})
+	}
+
+	envelope := newEnvelope(importPath, fullPath)
+	envelope.Schema = provider
+	envelope.Provisioner = provisioner
+	return writeDump(envelope, format, packageName)
+}
 
-	if err = util.Run(os.Environ(), fullPath, "go", "run", "dumper.go"); err != nil {
-		log.Printf("go run dumper.go exited with error: %s", err)
-		status = 1
+// Records where the dump came from, provenance that can't be determined
+// is left out. providerPath is empty when there is no source.
+func newEnvelope(provider, providerPath string) *schema.DumpEnvelope {
+	e := schema.NewDumpEnvelope(provider, extractedAt())
+	if providerPath == "" {
+		return e
 	}
 
-	if err = file.Close(); err != nil {
-		log.Printf("Error closing dumper.go: %s", err)
-		status = 1
+	if commit, err := util.Git(providerPath, "rev-parse", "HEAD"); err == nil {
+		e.Commit = commit
 	}
+	// fails unless HEAD is tagged
+	if tag, err := util.Git(providerPath, "describe", "--tags", "--exact-match"); err == nil {
+		e.Tag = tag
+	}
+	if version, err := util.SDKVersion(providerPath); err == nil {
+		e.SDKVersion = version
+	} else {
+		log.Printf("Error determining SDK version: %s", err)
+	}
+	return e
+}
 
-	if err = os.Remove(file.Name()); err != nil {
-		log.Printf("Error deleting %s: %s", file.Name(), err)
-		status = 1
+// SOURCE_DATE_EPOCH pins the timestamp so dumps are reproducible
+func extractedAt() time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if seconds, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+		log.Printf("Ignoring invalid SOURCE_DATE_EPOCH %q", epoch)
 	}
+	return time.Now().Truncate(time.Second)
+}
 
-	if moved {
-		if _, err = moveVendoredTerraform(fullPath, true); err != nil {
-			log.Printf("Error moving back vendored terraform: %s", err)
-			status = 1
+func writeDump(envelope *schema.DumpEnvelope, format, name string) int {
+	var err error
+	switch format {
+	case formatLegacy:
+		err = envelope.ToJSON(os.Stdout)
+	case formatTerraformJSON:
+		if envelope.Schema == nil {
+			log.Printf("The %s format only describes providers", formatTerraformJSON)
+			return 1
 		}
+		// Terraform's format carries its own format_version
+		err = convert.WriteTerraformJSON(os.Stdout, envelope.Schema, name)
+	default:
+		log.Printf("Unknown format %q", format)
+		return 1
+	}
+	if err != nil {
+		log.Printf("Error writing schema: %s", err)
+		return 1
 	}
 
-	return status
+	return 0
+}
+
+// Prints the findings, exits non-zero when one of them is an error
+func writeReport(report *schema.ValidationReport) int {
+	counts := make(map[string]int)
+	for _, f := range report.Findings {
+		fmt.Println(f)
+		counts[f.Severity]++
+	}
+	fmt.Printf("%d errors, %d warnings\n", counts[schema.FindingError], counts[schema.FindingWarning])
+
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
 }
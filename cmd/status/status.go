@@ -3,15 +3,12 @@ package status
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
-	"path/filepath"
 
 	"github.com/appilon/tfplugin/cmd/upgrade/golang"
 	"github.com/appilon/tfplugin/util"
 	"github.com/mitchellh/cli"
-	"github.com/radeksimko/go-mod-diff/go-src/cmd/go/_internal/modfile"
 )
 
 const CommandName = "status"
@@ -81,32 +78,21 @@ func (c *command) Run(args []string) int {
 		fmt.Println()
 	}()
 
-	if v, err := golang.DetectGoVersionFromTravis(providerPath); err == nil {
+	if v, err := golang.DetectGoVersion(providerPath); err == nil {
 		goVersion = v.Original()
 	} else {
 		log.Printf("Error determining go version: %s", err)
 	}
 
-	if _, err := os.Stat(filepath.Join(providerPath, "go.mod")); err == nil {
+	f, err := util.ReadGoMod(providerPath)
+	if err == nil {
 		usesModules = true
 	} else if !os.IsNotExist(err) {
-		log.Printf("Error checking for go.mod: %s", err)
+		log.Printf("Error reading go.mod file: %s", err)
 	}
 
 	// for now only pull sdk version if using go modules
 	if usesModules {
-		gomodPath := filepath.Join(providerPath, "go.mod")
-		data, err := ioutil.ReadFile(gomodPath)
-		if err != nil {
-			log.Printf("Error reading go.mod file: %s", err)
-			return 1
-		}
-		f, err := modfile.Parse(gomodPath, data, nil)
-		if err != nil {
-			log.Printf("Error parsing go.mod file: %s", err)
-			return 1
-		}
-
 		for _, r := range f.Require {
 			if r.Mod.Path == "github.com/hashicorp/terraform" {
 				sdkVersion = r.Mod.Version
@@ -1,10 +1,8 @@
 package golang
 
 import (
-	"errors"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
@@ -12,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/appilon/tfplugin/util"
+	"github.com/appilon/tfplugin/util/ci"
 	version "github.com/hashicorp/go-version"
 	"github.com/mitchellh/cli"
 )
@@ -41,6 +40,7 @@ func (c *command) Run(args []string) int {
 	var fmt bool
 	var fix bool
 	var encode bool
+	var dryRun bool
 	flags.StringVar(&toStr, "to", strings.TrimPrefix(runtime.Version(), "go"), "version of go upgrading to")
 	flags.StringVar(&provider, "provider", "", "provider to upgrade")
 	flags.BoolVar(&commit, "commit", false, "changes will be committed")
@@ -48,6 +48,7 @@ func (c *command) Run(args []string) int {
 	flags.BoolVar(&fmt, "fmt", false, "run go fmt on provider")
 	flags.BoolVar(&fix, "fix", false, "run go fix on provider")
 	flags.BoolVar(&encode, "encode", false, "encode version of go to .go-version")
+	flags.BoolVar(&dryRun, "dry-run", false, "print a diff of the changes and the commands that would run instead")
 	flags.Parse(args)
 
 	providerPath, err := util.FindProvider(provider)
@@ -62,32 +63,66 @@ func (c *command) Run(args []string) int {
 		return 1
 	}
 
-	if err := updateTravis(providerPath, majorMinor(to)); err != nil && !os.IsNotExist(err) {
-		log.Printf("Error updating .travis.yml: %s", err)
+	stage := util.NewStage(dryRun)
+
+	updated, err := ci.SetGoVersion(providerPath, majorMinor(to), stage)
+	if err != nil {
+		log.Printf("Error updating CI config: %s", err)
 		return 1
-	} else if err != nil {
-		log.Printf("No travis file, skipping...")
+	}
+	if len(updated) == 0 {
+		log.Printf("No CI config to update, skipping...")
+	}
+	var touched []string
+	for _, f := range updated {
+		touched = append(touched, f.Path)
 	}
 
-	// update all known versions found in readmes
-	if err := updateReadme(providerPath, "1.8", majorMinor(to)); err != nil {
-		log.Printf("Error updating README.md: %s", err)
+	if ok, err := updateGoMod(providerPath, to, stage); err != nil {
+		log.Printf("Error updating go.mod: %s", err)
 		return 1
+	} else if ok {
+		touched = append(touched, filepath.Join(providerPath, "go.mod"))
 	}
-	if err := updateReadme(providerPath, "1.9", majorMinor(to)); err != nil {
-		log.Printf("Error updating README.md: %s", err)
+
+	if ok, err := updateGoVersionFile(providerPath, to, encode, stage); err != nil {
+		log.Printf("Error writing %q to .go-version: %s", to, err)
+		return 1
+	} else if ok {
+		touched = append(touched, filepath.Join(providerPath, ".go-version"))
+	}
+
+	pinned, err := updatePins(providerPath, to, stage)
+	if err != nil {
+		log.Printf("Error updating go version pins: %s", err)
 		return 1
 	}
-	if err := updateReadme(providerPath, "1.10", majorMinor(to)); err != nil {
+	touched = append(touched, pinned...)
+
+	readme := filepath.Join(providerPath, "README.md")
+	if ok, err := updateReadme(providerPath, to, stage); err != nil {
 		log.Printf("Error updating README.md: %s", err)
 		return 1
+	} else if ok {
+		touched = append(touched, readme)
 	}
 
-	if encode {
-		if err := ioutil.WriteFile(filepath.Join(providerPath, ".go-version"), []byte(to.String()+"\n"), 0644); err != nil {
-			log.Printf("Error writing %q to .go-version", to)
+	if dryRun {
+		if err := stage.Diff(os.Stdout, providerPath); err != nil {
+			log.Printf("Error printing diff: %s", err)
 			return 1
 		}
+	} else if err := stage.Diff(os.Stdout, providerPath, readme); err != nil {
+		log.Printf("Error printing diff of README.md: %s", err)
+		return 1
+	}
+
+	for _, filename := range touched {
+		if dryRun {
+			log.Printf("Would update go in %s", filename)
+		} else {
+			log.Printf("Updated go in %s", filename)
+		}
 	}
 
 	if fmt || fix {
@@ -98,14 +133,14 @@ func (c *command) Run(args []string) int {
 		}
 
 		if fix {
-			if err := util.Run(os.Environ(), providerPath, "go", "tool", "fix", "./"+packageName); err != nil {
+			if err := stage.Run(os.Environ(), providerPath, "go", "tool", "fix", "./"+packageName); err != nil {
 				log.Printf("Error running go tool fix: %s", err)
 				return 1
 			}
 		}
 
 		if fmt {
-			if err := util.Run(os.Environ(), providerPath, "gofmt", "-s", "-w", "./"+packageName); err != nil {
+			if err := stage.Run(os.Environ(), providerPath, "gofmt", "-s", "-w", "./"+packageName); err != nil {
 				log.Printf("Error running gofmt: %s", err)
 				return 1
 			}
@@ -113,13 +148,25 @@ func (c *command) Run(args []string) int {
 	}
 
 	if commit {
-		if err = util.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
 			log.Printf("Error adding files: %s", err)
 			return 1
 		}
 
 		if message == "" {
-			message = "provider: Ensured Go " + majorMinor(to) + " in TravisCI and README\n"
+			var where []string
+			if len(updated) > 0 {
+				where = append(where, ciNames(updated))
+			}
+			for _, filename := range touched[len(updated):] {
+				if rel, err := filepath.Rel(providerPath, filename); err == nil && rel != ".go-version" {
+					where = append(where, rel)
+				}
+			}
+			message = "provider: Ensured Go " + majorMinor(to) + "\n"
+			if len(where) > 0 {
+				message = "provider: Ensured Go " + majorMinor(to) + " in " + strings.Join(where, ", ") + "\n"
+			}
 			if fix {
 				message += "provider: Run go fix\n"
 			}
@@ -131,7 +178,7 @@ func (c *command) Run(args []string) int {
 			}
 		}
 
-		if err = util.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
 			log.Printf("Error committing: %s", err)
 			return 1
 		}
@@ -145,59 +192,32 @@ func majorMinor(v *version.Version) string {
 	return fmt.Sprintf("%d.%d", segments[0], segments[1])
 }
 
-func updateReadme(providerPath string, search string, replace string) error {
-	filename := filepath.Join(providerPath, "README.md")
-	content, err := ioutil.ReadFile(filename)
-	if err != nil {
-		return err
-	}
-
-	out := strings.Replace(string(content), search, replace, -1)
-	return ioutil.WriteFile(filename, []byte(out), 0644)
-}
-
-func DetectGoVersionFromTravis(providerPath string) (*version.Version, error) {
-	_, content, err := util.ReadOneOf(providerPath, ".travis.yml", ".travis.yaml")
+// Version of go the provider's CI builds with
+func DetectGoVersion(providerPath string) (*version.Version, error) {
+	v, err := ci.DetectGoVersion(providerPath)
 	if err != nil {
 		return nil, err
 	}
-
-	lines := strings.Split(string(content), "\n")
-
-	if util.SearchLines(lines, "language: go", 0) == -1 {
-		return nil, errors.New("no 'language: go' in travis config")
-	}
-
-	goLine := util.SearchLines(lines, "go:", 0)
-	if goLine == -1 {
-		return nil, errors.New("no 'go:' in travis config")
-	}
-
-	v := strings.TrimSpace(lines[goLine+1])
-	v = strings.TrimLeft(v, ` -"`)
-	v = strings.TrimRight(v, ` "x.`)
-
 	return version.NewVersion(v)
 }
 
-func updateTravis(providerPath string, to string) error {
-	filename, content, err := util.ReadOneOf(providerPath, ".travis.yml", ".travis.yaml")
-	if err != nil {
-		return err
-	}
-
-	lines := strings.Split(string(content), "\n")
-
-	if util.SearchLines(lines, "language: go", 0) == -1 {
-		return errors.New("no 'language: go' in travis config")
-	}
+// Version of go the provider's TravisCI builds with.
+//
+// Deprecated: use DetectGoVersion, which reads the other CI configs when
+// there is no .travis.yml
+func DetectGoVersionFromTravis(providerPath string) (*version.Version, error) {
+	return DetectGoVersion(providerPath)
+}
 
-	goLine := util.SearchLines(lines, "go:", 0)
-	if goLine == -1 {
-		return errors.New("no 'go:' in travis config")
+// Names of the CIs of the changed files
+func ciNames(files []ci.File) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if name := f.Config.Name(); !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
 	}
-
-	lines = util.SetLine(lines, goLine+1, fmt.Sprintf(`  - "%s.x"`, to))
-
-	return ioutil.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+	return strings.Join(names, ", ")
 }
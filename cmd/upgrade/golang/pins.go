@@ -0,0 +1,137 @@
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/appilon/tfplugin/util"
+	version "github.com/hashicorp/go-version"
+	"github.com/radeksimko/go-mod-diff/go-src/cmd/go/_internal/modfile"
+)
+
+// A file pinning the version of go outside of CI configs, the second group
+// of each pattern is the version
+type pin struct {
+	// globs relative to the provider
+	globs    []string
+	patterns []*regexp.Regexp
+}
+
+const pinnedVersion = `(\d+\.\d+(?:\.\d+)?)`
+
+var pins = []pin{
+	{
+		globs: []string{"Dockerfile", "Dockerfile.*", "*.Dockerfile", "*/Dockerfile"},
+		patterns: []*regexp.Regexp{
+			// FROM golang:1.12, FROM --platform=$BUILDPLATFORM docker.io/library/golang:1.12-alpine
+			regexp.MustCompile(`(?im)^(\s*FROM\s+(?:--platform=\S+\s+)?(?:\S+/)?golang:)` + pinnedVersion),
+			regexp.MustCompile(`(?im)^(\s*ARG\s+GO_?VERSION=)` + pinnedVersion),
+		},
+	},
+	{
+		globs: []string{"GNUmakefile", "Makefile"},
+		patterns: []*regexp.Regexp{
+			// GO_VERSION ?= 1.12, go version | grep -q go1.12, only lines
+			// checking the version so recipes running go1.12 are left alone
+			regexp.MustCompile(`(?im)^(\s*GO_?VER(?:SION)?\s*[:?]?=\s*)` + pinnedVersion),
+			regexp.MustCompile(`(?m)(\bgo version\b.*?\bgo)` + pinnedVersion + `\b`),
+		},
+	},
+	{
+		globs: []string{".goreleaser.yml", ".goreleaser.yaml"},
+		patterns: []*regexp.Regexp{
+			// gobinary: go1.12, image: golang:1.12, GO_VERSION=1.12
+			regexp.MustCompile(`(gobinary:\s*["']?go)` + pinnedVersion),
+			regexp.MustCompile(`(golang:)` + pinnedVersion),
+			regexp.MustCompile(`(?i)(GO_?VERSION=)` + pinnedVersion),
+		},
+	},
+}
+
+// Sets the go directive of go.mod to the major.minor of to, unless it's
+// newer already
+func updateGoMod(providerPath string, to *version.Version, stage *util.Stage) (bool, error) {
+	filename := filepath.Join(providerPath, "go.mod")
+	data, err := stage.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	f, err := modfile.Parse(filename, data, nil)
+	if err != nil {
+		return false, err
+	}
+	v := majorMinor(to)
+	if f.Go != nil {
+		v = upgradeVersion(f.Go.Version, to)
+		if v == f.Go.Version {
+			return false, nil
+		}
+	}
+
+	if err := f.AddGoStmt(v); err != nil {
+		return false, err
+	}
+	content, err := f.Format()
+	if err != nil {
+		return false, err
+	}
+	return true, stage.WriteFile(filename, content)
+}
+
+// Sets .go-version to to, when the provider has one that's older or encode
+// is set
+func updateGoVersionFile(providerPath string, to *version.Version, encode bool, stage *util.Stage) (bool, error) {
+	filename := filepath.Join(providerPath, ".go-version")
+	content, err := stage.ReadFile(filename)
+	if os.IsNotExist(err) && !encode {
+		return false, nil
+	} else if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if old, err := version.NewVersion(strings.TrimSpace(string(content))); err == nil && !old.LessThan(to) {
+		return false, nil
+	}
+	out := to.String() + "\n"
+	return true, stage.WriteFile(filename, []byte(out))
+}
+
+// Replaces the versions pinned in Dockerfiles, makefiles and goreleaser
+// configs older than to, the files changed are returned. A pin with a patch
+// version gets the full version of to, major.minor otherwise.
+func updatePins(providerPath string, to *version.Version, stage *util.Stage) ([]string, error) {
+	var changed []string
+	for _, p := range pins {
+		for _, glob := range p.globs {
+			files, err := filepath.Glob(filepath.Join(providerPath, glob))
+			if err != nil {
+				return nil, err
+			}
+			for _, filename := range files {
+				content, err := stage.ReadFile(filename)
+				if err != nil {
+					return nil, err
+				}
+				out := string(content)
+				for _, r := range p.patterns {
+					out = r.ReplaceAllStringFunc(out, func(match string) string {
+						groups := r.FindStringSubmatch(match)
+						return groups[1] + upgradeVersion(groups[2], to)
+					})
+				}
+				if out == string(content) {
+					continue
+				}
+				if err := stage.WriteFile(filename, []byte(out)); err != nil {
+					return nil, err
+				}
+				changed = append(changed, filename)
+			}
+		}
+	}
+	return changed, nil
+}
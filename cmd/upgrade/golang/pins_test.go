@@ -0,0 +1,214 @@
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/appilon/tfplugin/util"
+	version "github.com/hashicorp/go-version"
+)
+
+// Writes a provider with the files given under a temporary directory
+func testProvider(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tfplugin-golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readStaged(t *testing.T, stage *util.Stage, filename string) string {
+	content, err := stage.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestUpdatePins(t *testing.T) {
+	cases := []struct {
+		name, file, content, want string
+	}{
+		{
+			"dockerfile",
+			"Dockerfile",
+			"FROM golang:1.12-alpine AS build\nARG GO_VERSION=1.12.7\nFROM alpine:3.9\n",
+			"FROM golang:1.14-alpine AS build\nARG GO_VERSION=1.14.2\nFROM alpine:3.9\n",
+		},
+		{
+			"dockerfile with platform",
+			"build/Dockerfile",
+			"FROM --platform=$BUILDPLATFORM docker.io/library/golang:1.13.8\n",
+			"FROM --platform=$BUILDPLATFORM docker.io/library/golang:1.14.2\n",
+		},
+		{
+			"newer dockerfile",
+			"Dockerfile.release",
+			"FROM golang:1.15\nARG GOVERSION=1.14.2\n",
+			"",
+		},
+		{
+			"makefile",
+			"GNUmakefile",
+			"GO_VERSION ?= 1.12\n\ngo-check:\n\t@go version | grep -q 'go1.12' || echo 'go 1.12 required'\n",
+			"GO_VERSION ?= 1.14\n\ngo-check:\n\t@go version | grep -q 'go1.14' || echo 'go 1.12 required'\n",
+		},
+		{
+			"makefile recipes",
+			"Makefile",
+			"test:\n\tgo1.12 test ./...\n\tGOFLAGS=-mod=vendor go1.12.7 vet ./...\n",
+			"",
+		},
+		{
+			"makefile dots",
+			"Makefile",
+			"GO_VERSION := 1x12\ncheck:\n\tgo version | grep go1x12\n",
+			"",
+		},
+		{
+			"newer makefile",
+			"GNUmakefile",
+			"GOVER = 1.16\ncheck:\n\tgo version | grep -q go1.16\n",
+			"",
+		},
+		{
+			"goreleaser",
+			".goreleaser.yml",
+			"builds:\n  - gobinary: go1.12.7\ndockers:\n  - image_templates: ['golang:1.12']\n    build_flag_templates: ['--build-arg=GO_VERSION=1.12']\n",
+			"builds:\n  - gobinary: go1.14.2\ndockers:\n  - image_templates: ['golang:1.14']\n    build_flag_templates: ['--build-arg=GO_VERSION=1.14']\n",
+		},
+		{
+			"newer goreleaser",
+			".goreleaser.yaml",
+			"builds:\n  - gobinary: \"go1.15\"\n",
+			"",
+		},
+		{
+			"not a pin",
+			"scripts/Makefile",
+			"check:\n\tgo version | grep -q go1.12\n",
+			"",
+		},
+	}
+
+	to := version.Must(version.NewVersion("1.14.2"))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := testProvider(t, map[string]string{c.file: c.content})
+			defer os.RemoveAll(dir)
+
+			stage := util.NewStage(true)
+			changed, err := updatePins(dir, to, stage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			filename := filepath.Join(dir, filepath.FromSlash(c.file))
+			if c.want == "" {
+				if len(changed) != 0 {
+					t.Errorf("updatePins changed %q:\n%s", changed, readStaged(t, stage, filename))
+				}
+				return
+			}
+			if len(changed) != 1 || changed[0] != filename {
+				t.Errorf("updatePins changed %q, want %s", changed, c.file)
+			}
+			if out := readStaged(t, stage, filename); out != c.want {
+				t.Errorf("updatePins wrote\n%s\nwant\n%s", out, c.want)
+			}
+		})
+	}
+}
+
+func TestUpdateGoMod(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string // no go.mod when empty
+		want    string // unchanged when empty
+	}{
+		{"no go.mod", "", ""},
+		{"older", "module x\n\ngo 1.12\n", "module x\n\ngo 1.14\n"},
+		{"no directive", "module x\n\nrequire github.com/hashicorp/terraform v0.11.11\n", "module x\n\nrequire github.com/hashicorp/terraform v0.11.11\n\ngo 1.14\n"},
+		{"same", "module x\n\ngo 1.14\n", ""},
+		{"newer", "module x\n\ngo 1.16\n", ""},
+	}
+
+	to := version.Must(version.NewVersion("1.14.2"))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := map[string]string{}
+			if c.content != "" {
+				files["go.mod"] = c.content
+			}
+			dir := testProvider(t, files)
+			defer os.RemoveAll(dir)
+
+			stage := util.NewStage(true)
+			ok, err := updateGoMod(dir, to, stage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (c.want != "") {
+				t.Errorf("updateGoMod = %t", ok)
+			}
+			if c.want != "" {
+				if out := readStaged(t, stage, filepath.Join(dir, "go.mod")); out != c.want {
+					t.Errorf("go.mod =\n%s\nwant\n%s", out, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateGoVersionFile(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string // no .go-version when empty
+		encode  bool
+		want    string // unchanged when empty
+	}{
+		{"no file", "", false, ""},
+		{"encoded", "", true, "1.14.2\n"},
+		{"older", "1.12.7\n", false, "1.14.2\n"},
+		{"older major.minor", "1.13", false, "1.14.2\n"},
+		{"same", "1.14.2\n", true, ""},
+		{"newer", "1.15\n", false, ""},
+		{"invalid", "go1.12\n", false, "1.14.2\n"},
+	}
+
+	to := version.Must(version.NewVersion("1.14.2"))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			files := map[string]string{}
+			if c.content != "" {
+				files[".go-version"] = c.content
+			}
+			dir := testProvider(t, files)
+			defer os.RemoveAll(dir)
+
+			stage := util.NewStage(true)
+			ok, err := updateGoVersionFile(dir, to, c.encode, stage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (c.want != "") {
+				t.Errorf("updateGoVersionFile = %t", ok)
+			}
+			if c.want != "" {
+				if out := readStaged(t, stage, filepath.Join(dir, ".go-version")); out != c.want {
+					t.Errorf(".go-version = %q, want %q", out, c.want)
+				}
+			}
+		})
+	}
+}
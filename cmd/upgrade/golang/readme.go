@@ -0,0 +1,60 @@
+package golang
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/appilon/tfplugin/util"
+	version "github.com/hashicorp/go-version"
+)
+
+// Mentions of a go version in a README, the second group is the version:
+// [Go](https://golang.org/doc/install) 1.11, Go 1.11, Go version 1.11, go1.11
+var readmeRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`(\[Go(?:lang)?\]\([^)]*\)\s+(?:version\s+)?v?)` + pinnedVersion),
+	regexp.MustCompile(`(\bGo(?:lang)?\s+(?:version\s+)?v?)` + pinnedVersion),
+	regexp.MustCompile(`(\bgo)` + pinnedVersion + `\b`),
+}
+
+// Upgrades the go versions README.md mentions that are older than to
+func updateReadme(providerPath string, to *version.Version, stage *util.Stage) (bool, error) {
+	filename := filepath.Join(providerPath, "README.md")
+	content, err := stage.ReadFile(filename)
+	if err != nil {
+		return false, err
+	}
+
+	lines := strings.Split(string(content), "\n")
+	changed := false
+	for i, line := range lines {
+		out := line
+		for _, r := range readmeRegexps {
+			out = r.ReplaceAllStringFunc(out, func(match string) string {
+				groups := r.FindStringSubmatch(match)
+				return groups[1] + upgradeVersion(groups[2], to)
+			})
+		}
+		if out == line {
+			continue
+		}
+		lines[i] = out
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+	return true, stage.WriteFile(filename, []byte(strings.Join(lines, "\n")))
+}
+
+// to in the precision of v when v is older, v otherwise
+func upgradeVersion(v string, to *version.Version) string {
+	old, err := version.NewVersion(v)
+	if err != nil || !old.LessThan(to) {
+		return v
+	}
+	if strings.Count(v, ".") == 2 {
+		return to.String()
+	}
+	return majorMinor(to)
+}
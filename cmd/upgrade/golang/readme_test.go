@@ -0,0 +1,81 @@
+package golang
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/appilon/tfplugin/util"
+	version "github.com/hashicorp/go-version"
+)
+
+func TestUpgradeVersion(t *testing.T) {
+	cases := []struct {
+		v, to, want string
+	}{
+		{"1.12", "1.14.2", "1.14"},
+		{"1.12.7", "1.14.2", "1.14.2"},
+		{"1.9", "1.14", "1.14"},
+		{"1.14", "1.14.2", "1.14"},
+		{"1.14.1", "1.14.2", "1.14.2"},
+		{"1.14.2", "1.14.2", "1.14.2"},
+		{"1.15", "1.14.2", "1.15"},
+		{"1.10", "1.9", "1.10"},
+		{"tip", "1.14", "tip"},
+	}
+	for _, c := range cases {
+		to := version.Must(version.NewVersion(c.to))
+		if out := upgradeVersion(c.v, to); out != c.want {
+			t.Errorf("upgradeVersion(%q, %s) = %q, want %q", c.v, c.to, out, c.want)
+		}
+	}
+}
+
+func TestUpdateReadme(t *testing.T) {
+	cases := []struct {
+		name, content, want string
+	}{
+		{
+			"requirements",
+			"Requirements\n------------\n\n-\t[Terraform](https://www.terraform.io/downloads.html) 0.10.x\n-\t[Go](https://golang.org/doc/install) 1.11 (to build the provider plugin)\n",
+			"Requirements\n------------\n\n-\t[Terraform](https://www.terraform.io/downloads.html) 0.10.x\n-\t[Go](https://golang.org/doc/install) 1.14 (to build the provider plugin)\n",
+		},
+		{
+			"prose",
+			"You'll need Go version 1.12.7 or go1.12 installed, Golang v1.11 works too.\n",
+			"You'll need Go version 1.14.2 or go1.14 installed, Golang v1.14 works too.\n",
+		},
+		{
+			"newer",
+			"-\t[Go](https://golang.org/doc/install) 1.15 (to build the provider plugin)\n",
+			"",
+		},
+		{
+			"no version",
+			"Using the provider\n----------------------\n\nSee the Go documentation.\n",
+			"",
+		},
+	}
+
+	to := version.Must(version.NewVersion("1.14.2"))
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := testProvider(t, map[string]string{"README.md": c.content})
+			defer os.RemoveAll(dir)
+
+			stage := util.NewStage(true)
+			ok, err := updateReadme(dir, to, stage)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != (c.want != "") {
+				t.Errorf("updateReadme = %t", ok)
+			}
+			if c.want != "" {
+				if out := readStaged(t, stage, filepath.Join(dir, "README.md")); out != c.want {
+					t.Errorf("README.md =\n%s\nwant\n%s", out, c.want)
+				}
+			}
+		})
+	}
+}
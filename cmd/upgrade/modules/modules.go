@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/appilon/tfplugin/util"
+	"github.com/appilon/tfplugin/util/yamledit"
 	"github.com/mitchellh/cli"
 )
 
@@ -36,9 +37,11 @@ func (c *command) Run(args []string) int {
 	var commit bool
 	var message string
 	var propose bool
+	var dryRun bool
 	flags.StringVar(&provider, "provider", "", "provider to switch to go modules")
 	flags.BoolVar(&propose, "propose", false, "open issue proposing switch to go modules")
 	flags.BoolVar(&commit, "commit", false, "changes will be committed")
+	flags.BoolVar(&dryRun, "dry-run", false, "print a diff of the changes and the commands that would run instead")
 	flags.StringVar(&message, "message", "deps: use go modules for dep mgmt\nrun go mod tidy\nremove govendor from makefile and travis config\nset appropriate env vars for go modules\n", "specify commit message")
 	flags.Parse(args)
 
@@ -49,7 +52,7 @@ func (c *command) Run(args []string) int {
 	}
 
 	if propose {
-		return proposeGoModules(providerPath)
+		return proposeGoModules(providerPath, dryRun)
 	}
 
 	// check if repo already uses modules
@@ -70,69 +73,90 @@ func (c *command) Run(args []string) int {
 		return 1
 	}
 
-	// switch to modules
+	// switch to modules, a dry run switches a scratch copy
+	stage := util.NewStage(dryRun)
+	workPath, done, err := stage.WorkPath(providerPath)
+	if err != nil {
+		log.Printf("Error copying %s for dry run: %s", providerPath, err)
+		return 1
+	}
+	defer done()
 
-	if err := util.Run(Env(), providerPath, "go", "mod", "init"); err != nil {
-		log.Printf("Error running go mod init in %s: %s", providerPath, err)
+	initArgs := []string{"mod", "init"}
+	if dryRun {
+		// outside of GOPATH the scratch copy's module path can't be inferred
+		if importPath, err := util.GopathImportPath(providerPath); err == nil {
+			initArgs = append(initArgs, importPath)
+		}
+	}
+	if err := util.Run(Env(), workPath, "go", initArgs...); err != nil {
+		log.Printf("Error running go mod init in %s: %s", workPath, err)
 		return 1
 	}
 
-	if err := os.RemoveAll(filepath.Join(providerPath, "Gopkg.lock")); err != nil {
+	if err := os.RemoveAll(filepath.Join(workPath, "Gopkg.lock")); err != nil {
 		log.Printf("Error deleting Gopkg.lock: %s", err)
 		return 1
 	}
 
-	if err := os.RemoveAll(filepath.Join(providerPath, "Gopkg.toml")); err != nil {
+	if err := os.RemoveAll(filepath.Join(workPath, "Gopkg.toml")); err != nil {
 		log.Printf("Error deleting Gopkg.toml: %s", err)
 		return 1
 	}
 
-	if err := os.RemoveAll(filepath.Join(providerPath, "vendor")); err != nil {
-		log.Printf("Error purging vendor/ from %s: %s", providerPath, err)
+	if err := os.RemoveAll(filepath.Join(workPath, "vendor")); err != nil {
+		log.Printf("Error purging vendor/ from %s: %s", workPath, err)
 		return 1
 	}
 
-	if err := util.Run(Env(), providerPath, "go", "mod", "tidy"); err != nil {
-		log.Printf("Error running go mod tidy in %s: %s", providerPath, err)
+	if err := util.Run(Env(), workPath, "go", "mod", "tidy"); err != nil {
+		log.Printf("Error running go mod tidy in %s: %s", workPath, err)
 		return 1
 	}
 
-	if err := util.Run(Env(), providerPath, "go", "mod", "vendor"); err != nil {
-		log.Printf("Error running go mod vendor in %s: %s", providerPath, err)
+	if err := util.Run(Env(), workPath, "go", "mod", "vendor"); err != nil {
+		log.Printf("Error running go mod vendor in %s: %s", workPath, err)
 		return 1
 	}
 
-	if err := removeGovendorDepFromTravis(providerPath); err != nil && !os.IsNotExist(err) {
-		log.Printf("Error removing govendor from travis config in %s: %s", providerPath, err)
+	if err := removeGovendorDepFromTravis(workPath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error removing govendor from travis config in %s: %s", workPath, err)
 		return 1
 	} else if err != nil {
 		log.Printf("No travis file.. skipping step")
 	}
 
-	if err := removeGovendorDepFromMakefile(providerPath); err != nil {
-		log.Printf("Error removing govendor from makefile in %s: %s", providerPath, err)
+	if err := removeGovendorDepFromMakefile(workPath); err != nil {
+		log.Printf("Error removing govendor from makefile in %s: %s", workPath, err)
 		return 1
 	}
 
-	if err := setModulesEnvVarsInTravis(providerPath); err != nil && !os.IsNotExist(err) {
-		log.Printf("Error setting module related env vars in travis file %s: %s", providerPath, err)
+	if err := setModulesEnvVarsInTravis(workPath); err != nil && !os.IsNotExist(err) {
+		log.Printf("Error setting module related env vars in travis file %s: %s", workPath, err)
 		return 1
 	} else if err != nil {
 		log.Printf("No travis file.. skipping step")
 	}
 
-	if err := turnOffModulesForCertainCommandsInMakefile(providerPath); err != nil {
-		log.Printf("Error disabling modules for certain commands in makefile %s: %s", providerPath, err)
+	if err := turnOffModulesForCertainCommandsInMakefile(workPath); err != nil {
+		log.Printf("Error disabling modules for certain commands in makefile %s: %s", workPath, err)
 		return 1
 	}
 
+	if dryRun {
+		if err := util.DiffTrees(os.Stdout, providerPath, workPath); err != nil {
+			log.Printf("Error printing diff: %s", err)
+			return 1
+		}
+	}
+
 	if commit {
-		if err = util.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
 			log.Printf("Error adding files: %s", err)
 			return 1
 		}
 
-		if err = util.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
 			log.Printf("Error committing: %s", err)
 			return 1
 		}
@@ -142,33 +166,21 @@ func (c *command) Run(args []string) int {
 }
 
 func setModulesEnvVarsInTravis(providerPath string) error {
-	filename, content, err := util.ReadOneOf(providerPath, ".travis.yml", ".travis.yaml")
+	filename, doc, err := util.ReadTravis(providerPath)
 	if err != nil {
 		return err
 	}
-	lines := strings.Split(string(content), "\n")
-	// need to ensure newline before EOF
-	if lines[len(lines)-1] != "" {
-		lines = append(lines, "")
-	}
-
-	if util.SearchLines(lines, "GOFLAGS=-mod=vendor", 0) == -1 {
-		envLine := util.SearchLines(lines, "env:", 0)
-		if envLine == -1 {
-			last := len(lines) - 1
-			lines = util.InsertLineBefore(lines, last, "env:")
-			envLine = util.SearchLines(lines, "env:", last)
-		}
 
-		globalLine := util.SearchLines(lines, "global:", envLine)
-		if globalLine == -1 {
-			lines = util.InsertLineBefore(lines, envLine+1, "  - GOFLAGS=-mod=vendor GO111MODULE=on")
-		} else {
-			lines = util.InsertLineBefore(lines, globalLine+1, "    - GOFLAGS=-mod=vendor GO111MODULE=on")
+	if !strings.Contains(string(doc.Bytes()), "GOFLAGS=-mod=vendor") {
+		switch doc.Kind("env") {
+		case yamledit.KindScalar, yamledit.KindSequence:
+			// a plain env is the build matrix, global entries go beside it
+			doc.Nest("matrix", "env")
 		}
+		doc.Append("GOFLAGS=-mod=vendor GO111MODULE=on", "env", "global")
 	}
 
-	return ioutil.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+	return ioutil.WriteFile(filename, doc.Bytes(), 0644)
 }
 
 func turnOffModulesForCertainCommandsInMakefile(providerPath string) error {
@@ -198,21 +210,30 @@ func removeLineContaining(lines []string, search string) []string {
 	return lines
 }
 
+// Build steps of a job in travis config
+var travisSteps = []string{"before_install", "install", "before_script", "script", "after_success"}
+
 func removeGovendorDepFromTravis(providerPath string) error {
-	filename, content, err := util.ReadOneOf(providerPath, ".travis.yml", ".travis.yaml")
+	filename, doc, err := util.ReadTravis(providerPath)
 	if err != nil {
 		return err
 	}
-	lines := strings.Split(string(content), "\n")
-
-	lines = removeLineContaining(lines, "github.com/kardianos/govendor")
-	lines = removeLineContaining(lines, "github.com/golang/dep/cmd/dep")
 
-	if vendorStatusLine := util.SearchLines(lines, "vendor-status", 0); vendorStatusLine > -1 {
-		lines = util.DeleteLines(lines, vendorStatusLine)
+	govendor := func(step string) bool {
+		for _, search := range []string{"github.com/kardianos/govendor", "github.com/golang/dep/cmd/dep", "vendor-status"} {
+			if strings.Contains(step, search) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, job := range util.TravisJobPaths(doc) {
+		for _, step := range travisSteps {
+			doc.RemoveItems(govendor, append(job, step)...)
+		}
 	}
 
-	return ioutil.WriteFile(filename, []byte(strings.Join(lines, "\n")), 0644)
+	return ioutil.WriteFile(filename, doc.Bytes(), 0644)
 }
 
 func removeGovendorDepFromMakefile(providerPath string) error {
@@ -31,7 +31,7 @@ $ GO111MODULE=on go mod vendor
 
 Thank you sincerely for all your time, contributions, and cooperation!`
 
-func proposeGoModules(providerPath string) int {
+func proposeGoModules(providerPath string, dryRun bool) int {
 	if _, err := os.Stat(filepath.Join(providerPath, "go.mod")); !os.IsNotExist(err) {
 		log.Printf("%s/go.mod exists or some other error occured... skipping", providerPath)
 		return 0
@@ -51,6 +51,11 @@ func proposeGoModules(providerPath string) int {
 		return 0
 	}
 
+	if dryRun {
+		log.Printf("Would open GH issue %q on %s/%s", IssueTitle, owner, repo)
+		return 0
+	}
+
 	issueNo, err := openIssue(owner, repo, IssueTitle, issueBody)
 
 	if err != nil {
@@ -35,11 +35,13 @@ func (c *command) Run(args []string) int {
 	var depTool string
 	var commit bool
 	var message string
+	var dryRun bool
 	flags.StringVar(&to, "to", "latest", "version of the terraform sdk to upgrade to")
 	flags.StringVar(&provider, "provider", "", "provider to upgrade")
 	flags.StringVar(&depTool, "dep-tool", "modules", "dependency tool for the provider")
 	flags.BoolVar(&commit, "commit", false, "changes will be committed")
 	flags.StringVar(&message, "message", "", "specify commit message")
+	flags.BoolVar(&dryRun, "dry-run", false, "print a diff of the changes and the commands that would run instead")
 	flags.Parse(args)
 
 	providerPath, err := util.FindProvider(provider)
@@ -48,13 +50,29 @@ func (c *command) Run(args []string) int {
 		return 1
 	}
 
-	if err = updateSDK(providerPath, to, depTool); err != nil {
+	// a dry run updates a scratch copy
+	stage := util.NewStage(dryRun)
+	workPath, done, err := stage.WorkPath(providerPath)
+	if err != nil {
+		log.Printf("Error copying %s for dry run: %s", providerPath, err)
+		return 1
+	}
+	defer done()
+
+	if err = updateSDK(workPath, to, depTool); err != nil {
 		log.Printf("Error updating sdk to %s: %s", to, err)
 		return 1
 	}
 
+	if dryRun {
+		if err := util.DiffTrees(os.Stdout, providerPath, workPath); err != nil {
+			log.Printf("Error printing diff: %s", err)
+			return 1
+		}
+	}
+
 	if commit {
-		if err = util.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "add", "--all"); err != nil {
 			log.Printf("Error adding files: %s", err)
 			return 1
 		}
@@ -74,7 +92,7 @@ func (c *command) Run(args []string) int {
 			message = fmt.Sprintf("deps: %s@%s\nUpdated via: %s\n", TerraformRepo, to, command)
 		}
 
-		if err = util.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
+		if err = stage.Run(os.Environ(), providerPath, "git", "commit", "-m", message); err != nil {
 			log.Printf("Error committing: %s", err)
 			return 1
 		}
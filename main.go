@@ -5,7 +5,9 @@ import (
 	"os"
 
 	"github.com/appilon/tfplugin/cmd/docs"
+	"github.com/appilon/tfplugin/cmd/example"
 	"github.com/appilon/tfplugin/cmd/schema"
+	schemadiff "github.com/appilon/tfplugin/cmd/schema/diff"
 	"github.com/appilon/tfplugin/cmd/status"
 	"github.com/appilon/tfplugin/cmd/upgrade/golang"
 	"github.com/appilon/tfplugin/cmd/upgrade/modules"
@@ -19,13 +21,15 @@ func main() {
 	c := cli.NewCLI("tfplugin", "0.2.0")
 	c.Args = os.Args[1:]
 	c.Commands = map[string]cli.CommandFactory{
-		schema.CommandName:  schema.CommandFactory,
-		docs.CommandName:    docs.CommandFactory,
-		golang.CommandName:  golang.CommandFactory,
-		sdk.CommandName:     sdk.CommandFactory,
-		modules.CommandName: modules.CommandFactory,
-		pr.CommandName:      pr.CommandFactory,
-		status.CommandName:  status.CommandFactory,
+		schema.CommandName:     schema.CommandFactory,
+		schemadiff.CommandName: schemadiff.CommandFactory,
+		docs.CommandName:       docs.CommandFactory,
+		example.CommandName:    example.CommandFactory,
+		golang.CommandName:     golang.CommandFactory,
+		sdk.CommandName:        sdk.CommandFactory,
+		modules.CommandName:    modules.CommandFactory,
+		pr.CommandName:         pr.CommandFactory,
+		status.CommandName:     status.CommandFactory,
 	}
 
 	exitStatus, err := c.Run()
@@ -0,0 +1,101 @@
+// Package convert translates dumps to and from the schema model of
+// Terraform core. It's kept out of package schema, which is compiled into
+// the dumper against the provider's own SDK where these packages may not exist.
+package convert
+
+import (
+	"github.com/appilon/tfplugin/schema"
+	"github.com/hashicorp/terraform/config/configschema"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Converts the schema returned by terraform.ResourceProvider.GetSchema to
+// schema.ProviderDump. The core schema only describes structure, anything
+// helper/schema specific (defaults, ForceNew, descriptions, functions...)
+// is left unset.
+func NewProviderDump(i *terraform.ProviderSchema) *schema.ProviderDump {
+	p := &schema.ProviderDump{}
+	if i.Provider != nil {
+		p.Schema = NewResourceDump(i.Provider).Schema
+	} else {
+		p.Schema = make(map[string]*schema.SchemaDump)
+	}
+	p.DataSourcesMap = make(map[string]*schema.ResourceDump)
+	for key, value := range i.DataSources {
+		p.DataSourcesMap[key] = NewResourceDump(value)
+	}
+	p.ResourcesMap = make(map[string]*schema.ResourceDump)
+	for key, value := range i.ResourceTypes {
+		p.ResourcesMap[key] = NewResourceDump(value)
+	}
+	return p
+}
+
+// Converts terraform/config/configschema.Block to schema.ResourceDump
+func NewResourceDump(i *configschema.Block) *schema.ResourceDump {
+	r := &schema.ResourceDump{}
+	r.Schema = make(map[string]*schema.SchemaDump)
+	for key, value := range i.Attributes {
+		s := newSchemaDumpFromType(value.Type)
+		s.Optional = value.Optional
+		s.Required = value.Required
+		s.Computed = value.Computed
+		s.Sensitive = value.Sensitive
+		r.Schema[key] = s
+	}
+	for key, value := range i.BlockTypes {
+		r.Schema[key] = newSchemaDumpFromNestedBlock(value)
+	}
+	return r
+}
+
+func newSchemaDumpFromNestedBlock(i *configschema.NestedBlock) *schema.SchemaDump {
+	s := &schema.SchemaDump{}
+	switch i.Nesting {
+	case configschema.NestingSingle:
+		s.Type = tfschema.TypeList
+		s.MaxItems = 1
+	case configschema.NestingList:
+		s.Type = tfschema.TypeList
+	case configschema.NestingSet:
+		s.Type = tfschema.TypeSet
+	case configschema.NestingMap:
+		s.Type = tfschema.TypeMap
+	}
+	if i.MinItems > s.MinItems {
+		s.MinItems = i.MinItems
+	}
+	if i.MaxItems > s.MaxItems {
+		s.MaxItems = i.MaxItems
+	}
+	// helper/schema lowers Required blocks to MinItems = 1
+	s.Required = s.MinItems > 0
+	s.Optional = !s.Required
+	s.Elem = NewResourceDump(&i.Block)
+	return s
+}
+
+// cty.Number can't tell TypeInt from TypeFloat, TypeFloat is assumed
+func newSchemaDumpFromType(ty cty.Type) *schema.SchemaDump {
+	s := &schema.SchemaDump{}
+	switch {
+	case ty == cty.String:
+		s.Type = tfschema.TypeString
+	case ty == cty.Bool:
+		s.Type = tfschema.TypeBool
+	case ty == cty.Number:
+		s.Type = tfschema.TypeFloat
+	case ty.IsListType():
+		s.Type = tfschema.TypeList
+		s.Elem = newSchemaDumpFromType(ty.ElementType())
+	case ty.IsSetType():
+		s.Type = tfschema.TypeSet
+		s.Elem = newSchemaDumpFromType(ty.ElementType())
+	case ty.IsMapType():
+		s.Type = tfschema.TypeMap
+		s.Elem = newSchemaDumpFromType(ty.ElementType())
+	}
+	return s
+}
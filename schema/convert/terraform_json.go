@@ -0,0 +1,270 @@
+package convert
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/appilon/tfplugin/schema"
+	"github.com/hashicorp/terraform/config/configschema"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
+)
+
+// Version of the `terraform providers schema -json` format produced
+const TerraformJSONFormatVersion = "0.1"
+
+// Mirrors the layout of `terraform providers schema -json`
+type terraformProviderSchemas struct {
+	FormatVersion string                              `json:"format_version"`
+	Schemas       map[string]*terraformProviderSchema `json:"provider_schemas"`
+}
+
+type terraformProviderSchema struct {
+	Provider          *terraformSchema            `json:"provider,omitempty"`
+	ResourceSchemas   map[string]*terraformSchema `json:"resource_schemas,omitempty"`
+	DataSourceSchemas map[string]*terraformSchema `json:"data_source_schemas,omitempty"`
+}
+
+type terraformSchema struct {
+	Version int             `json:"version"`
+	Block   *terraformBlock `json:"block,omitempty"`
+}
+
+type terraformBlock struct {
+	Attributes map[string]*terraformAttribute `json:"attributes,omitempty"`
+	BlockTypes map[string]*terraformBlockType `json:"block_types,omitempty"`
+	Deprecated bool                           `json:"deprecated,omitempty"`
+}
+
+type terraformAttribute struct {
+	AttributeType json.RawMessage `json:"type,omitempty"`
+	Description   string          `json:"description,omitempty"`
+	Required      bool            `json:"required,omitempty"`
+	Optional      bool            `json:"optional,omitempty"`
+	Computed      bool            `json:"computed,omitempty"`
+	Sensitive     bool            `json:"sensitive,omitempty"`
+	Deprecated    bool            `json:"deprecated,omitempty"`
+}
+
+type terraformBlockType struct {
+	NestingMode string          `json:"nesting_mode,omitempty"`
+	Block       *terraformBlock `json:"block,omitempty"`
+	MinItems    int             `json:"min_items,omitempty"`
+	MaxItems    int             `json:"max_items,omitempty"`
+}
+
+// Writes the dump in the format of `terraform providers schema -json`,
+// name is the provider name ex: netlify
+func WriteTerraformJSON(w io.Writer, p *schema.ProviderDump, name string) error {
+	if err := checkResolved(p); err != nil {
+		return err
+	}
+
+	provider, err := newTerraformSchema(&schema.ResourceDump{Schema: p.Schema})
+	if err != nil {
+		return err
+	}
+	ps := &terraformProviderSchema{
+		Provider:          provider,
+		ResourceSchemas:   make(map[string]*terraformSchema),
+		DataSourceSchemas: make(map[string]*terraformSchema),
+	}
+	for key, value := range p.ResourcesMap {
+		if ps.ResourceSchemas[key], err = newTerraformSchema(value); err != nil {
+			return err
+		}
+	}
+	for key, value := range p.DataSourcesMap {
+		if ps.DataSourceSchemas[key], err = newTerraformSchema(value); err != nil {
+			return err
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(&terraformProviderSchemas{
+		FormatVersion: TerraformJSONFormatVersion,
+		Schemas:       map[string]*terraformProviderSchema{name: ps},
+	})
+}
+
+// Fields of a SchemaDump Terraform core is given
+var coreFields = []string{schema.UnresolvedAll, "Type", "Optional", "Required", "Computed", "Sensitive", "MinItems", "MaxItems", "Elem"}
+
+// helper/schema panics on schemas static extraction couldn't fully resolve,
+// those dumps are rejected naming the first incomplete attribute
+func checkResolved(p *schema.ProviderDump) error {
+	if len(p.Unresolved) > 0 {
+		return fmt.Errorf("provider: %s could not be resolved", strings.Join(p.Unresolved, ", "))
+	}
+	if err := checkResolvedSchema("provider", p.Schema); err != nil {
+		return err
+	}
+	for _, kind := range []struct {
+		prefix    string
+		resources map[string]*schema.ResourceDump
+	}{{"resource", p.ResourcesMap}, {"data", p.DataSourcesMap}} {
+		names := make([]string, 0, len(kind.resources))
+		for name := range kind.resources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if err := checkResolvedResource(kind.prefix+"."+name, kind.resources[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkResolvedResource(path string, r *schema.ResourceDump) error {
+	for _, field := range r.Unresolved {
+		if field == schema.UnresolvedAll || field == "Schema" || field == "SchemaVersion" {
+			return fmt.Errorf("%s: %s could not be resolved", path, field)
+		}
+	}
+	return checkResolvedSchema(path, r.Schema)
+}
+
+func checkResolvedSchema(path string, schemas map[string]*schema.SchemaDump) error {
+	keys := make([]string, 0, len(schemas))
+	for key := range schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if err := checkResolvedAttribute(path+"."+key, schemas[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkResolvedAttribute(path string, s *schema.SchemaDump) error {
+	for _, field := range s.Unresolved {
+		for _, core := range coreFields {
+			if field == core {
+				return fmt.Errorf("%s: %s could not be resolved", path, field)
+			}
+		}
+	}
+	if s.Type == tfschema.TypeInvalid {
+		return fmt.Errorf("%s: Type is not set", path)
+	}
+	switch elem := s.Elem.(type) {
+	case *schema.SchemaDump:
+		return checkResolvedAttribute(path, elem)
+	case *schema.ResourceDump:
+		return checkResolvedResource(path, elem)
+	}
+	return nil
+}
+
+// Lowers the dump through helper/schema to the model used by Terraform core
+func coreConfigSchema(r *schema.ResourceDump) *configschema.Block {
+	return toResource(r).CoreConfigSchema()
+}
+
+// Rebuilds the parts of schema.Resource relevant to Terraform core
+func toResource(r *schema.ResourceDump) *tfschema.Resource {
+	i := &tfschema.Resource{}
+	i.Schema = make(map[string]*tfschema.Schema)
+	for key, value := range r.Schema {
+		i.Schema[key] = toSchema(value)
+	}
+	return i
+}
+
+// Rebuilds the parts of schema.Schema relevant to Terraform core
+func toSchema(s *schema.SchemaDump) *tfschema.Schema {
+	i := &tfschema.Schema{}
+	i.Type = s.Type
+	i.Optional = s.Optional
+	i.Required = s.Required
+	i.Computed = s.Computed
+	i.Sensitive = s.Sensitive
+	i.MinItems = s.MinItems
+	i.MaxItems = s.MaxItems
+	switch elem := s.Elem.(type) {
+	case *schema.SchemaDump:
+		i.Elem = toSchema(elem)
+	case *schema.ResourceDump:
+		i.Elem = toResource(elem)
+	}
+	return i
+}
+
+func newTerraformSchema(r *schema.ResourceDump) (*terraformSchema, error) {
+	block, err := newTerraformBlock(coreConfigSchema(r), r)
+	if err != nil {
+		return nil, err
+	}
+	return &terraformSchema{
+		Version: r.SchemaVersion,
+		Block:   block,
+	}, nil
+}
+
+// configschema does not carry descriptions or deprecations in this version
+// of the SDK, those are filled in from the dump
+func newTerraformBlock(block *configschema.Block, r *schema.ResourceDump) (*terraformBlock, error) {
+	b := &terraformBlock{}
+	b.Deprecated = r.DeprecationMessage != ""
+	if len(block.Attributes) > 0 {
+		b.Attributes = make(map[string]*terraformAttribute)
+	}
+	for key, value := range block.Attributes {
+		ty, err := value.Type.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		s := r.Schema[key]
+		b.Attributes[key] = &terraformAttribute{
+			AttributeType: ty,
+			Description:   s.Description,
+			Required:      value.Required,
+			Optional:      value.Optional,
+			Computed:      value.Computed,
+			Sensitive:     value.Sensitive,
+			Deprecated:    s.Deprecated != "",
+		}
+	}
+	if len(block.BlockTypes) > 0 {
+		b.BlockTypes = make(map[string]*terraformBlockType)
+	}
+	for key, value := range block.BlockTypes {
+		elem, ok := r.Schema[key].Elem.(*schema.ResourceDump)
+		if !ok {
+			return nil, fmt.Errorf("%s: block without a *schema.Resource Elem", key)
+		}
+		nested, err := newTerraformBlock(&value.Block, elem)
+		if err != nil {
+			return nil, err
+		}
+		b.BlockTypes[key] = &terraformBlockType{
+			NestingMode: nestingMode(value.Nesting),
+			Block:       nested,
+			MinItems:    value.MinItems,
+			MaxItems:    value.MaxItems,
+		}
+	}
+	return b, nil
+}
+
+func nestingMode(mode configschema.NestingMode) string {
+	switch mode {
+	case configschema.NestingSingle:
+		return "single"
+	case configschema.NestingList:
+		return "list"
+	case configschema.NestingSet:
+		return "set"
+	case configschema.NestingMap:
+		return "map"
+	default:
+		return ""
+	}
+}
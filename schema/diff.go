@@ -0,0 +1,362 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// How a change affects existing configurations and state
+type Severity string
+
+const (
+	SeverityBreaking       Severity = "breaking"
+	SeverityStateMigration Severity = "state-migration"
+	SeverityNonBreaking    Severity = "non-breaking"
+)
+
+// Kinds of changes found between two dumps
+const (
+	ChangeResourceAdded          = "resource-added"
+	ChangeResourceRemoved        = "resource-removed"
+	ChangeResourceDeprecated     = "resource-deprecated"
+	ChangeSchemaVersionBumped    = "schema-version-bumped"
+	ChangeSchemaVersionLowered   = "schema-version-lowered"
+	ChangeMigrateStateMissing    = "migrate-state-missing"
+	ChangeImporterAdded          = "importer-added"
+	ChangeImporterRemoved        = "importer-removed"
+	ChangeAttributeAdded         = "attribute-added"
+	ChangeAttributeRemoved       = "attribute-removed"
+	ChangeOptionalToRequired     = "optional-to-required"
+	ChangeRequiredToOptional     = "required-to-optional"
+	ChangeComputedAdded          = "computed-added"
+	ChangeComputedRemoved        = "computed-removed"
+	ChangeTypeChanged            = "type-changed"
+	ChangeElemChanged            = "elem-changed"
+	ChangeForceNewAdded          = "force-new-added"
+	ChangeForceNewRemoved        = "force-new-removed"
+	ChangeDefaultChanged         = "default-changed"
+	ChangeMaxItemsTightened      = "max-items-tightened"
+	ChangeMaxItemsLoosened       = "max-items-loosened"
+	ChangeMinItemsTightened      = "min-items-tightened"
+	ChangeMinItemsLoosened       = "min-items-loosened"
+	ChangeConflictsWithAdded     = "conflicts-with-added"
+	ChangeAttributeDeprecated    = "attribute-deprecated"
+	ChangeAttributeMarkedRemoved = "attribute-marked-removed"
+	ChangeSensitiveChanged       = "sensitive-changed"
+	ChangeStateFuncChanged       = "state-func-changed"
+	ChangeSetFuncChanged         = "set-func-changed"
+)
+
+// A single difference between two provider dumps
+type Change struct {
+	// Dotted location ex: resource.aws_instance.ebs_block_device.volume_size
+	Path     string
+	Kind     string
+	Severity Severity
+	Message  string
+	Old      interface{}
+	New      interface{}
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("[%s] %s: %s", c.Severity, c.Path, c.Message)
+}
+
+// Compares two provider dumps, typically the last release against HEAD.
+// Changes are ordered by path so the result is stable.
+func DiffProviders(old, new *ProviderDump) []*Change {
+	d := &differ{}
+	d.attributes("provider", old.Schema, new.Schema)
+	d.resources("resource", "resource", old.ResourcesMap, new.ResourcesMap)
+	d.resources("data", "data source", old.DataSourcesMap, new.DataSourcesMap)
+	return d.changes
+}
+
+// Same as DiffProviders for provisioners
+func DiffProvisioners(old, new *ProvisionerDump) []*Change {
+	d := &differ{}
+	d.attributes("provisioner", old.Schema, new.Schema)
+	d.attributes("connection", old.ConnSchema, new.ConnSchema)
+	return d.changes
+}
+
+// Reports whether any of the changes is breaking
+func HasBreakingChanges(changes []*Change) bool {
+	for _, c := range changes {
+		if c.Severity == SeverityBreaking {
+			return true
+		}
+	}
+	return false
+}
+
+type differ struct {
+	changes []*Change
+}
+
+func (d *differ) add(path, kind string, severity Severity, old, new interface{}, format string, a ...interface{}) {
+	d.changes = append(d.changes, &Change{
+		Path:     path,
+		Kind:     kind,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+		Old:      old,
+		New:      new,
+	})
+}
+
+func (d *differ) resources(prefix, noun string, old, new map[string]*ResourceDump) {
+	for _, name := range unionKeys(old, new) {
+		path := prefix + "." + name
+		o, n := old[name], new[name]
+		switch {
+		case n == nil:
+			d.add(path, ChangeResourceRemoved, SeverityBreaking, nil, nil, "%s removed", noun)
+		case o == nil:
+			d.add(path, ChangeResourceAdded, SeverityNonBreaking, nil, nil, "%s added", noun)
+		default:
+			d.resource(path, o, n)
+		}
+	}
+}
+
+func (d *differ) resource(path string, old, new *ResourceDump) {
+	if unresolved(old.Unresolved, new.Unresolved, UnresolvedAll) {
+		return
+	}
+
+	if old.DeprecationMessage == "" && new.DeprecationMessage != "" {
+		d.add(path, ChangeResourceDeprecated, SeverityNonBreaking, nil, new.DeprecationMessage, "deprecated: %s", new.DeprecationMessage)
+	}
+
+	if !unresolved(old.Unresolved, new.Unresolved, "SchemaVersion") {
+		switch {
+		case new.SchemaVersion > old.SchemaVersion && new.MigrateState == nil:
+			d.add(path, ChangeMigrateStateMissing, SeverityBreaking, old.SchemaVersion, new.SchemaVersion,
+				"SchemaVersion bumped from %d to %d without MigrateState", old.SchemaVersion, new.SchemaVersion)
+		case new.SchemaVersion > old.SchemaVersion:
+			d.add(path, ChangeSchemaVersionBumped, SeverityStateMigration, old.SchemaVersion, new.SchemaVersion,
+				"SchemaVersion bumped from %d to %d", old.SchemaVersion, new.SchemaVersion)
+		case new.SchemaVersion < old.SchemaVersion:
+			d.add(path, ChangeSchemaVersionLowered, SeverityBreaking, old.SchemaVersion, new.SchemaVersion,
+				"SchemaVersion lowered from %d to %d, existing state can't be read", old.SchemaVersion, new.SchemaVersion)
+		}
+	}
+
+	if !unresolved(old.Unresolved, new.Unresolved, "Importer") {
+		if old.Importer != nil && new.Importer == nil {
+			d.add(path, ChangeImporterRemoved, SeverityBreaking, nil, nil, "import support removed")
+		} else if old.Importer == nil && new.Importer != nil {
+			d.add(path, ChangeImporterAdded, SeverityNonBreaking, nil, nil, "import support added")
+		}
+	}
+
+	d.attributes(path, old.Schema, new.Schema)
+}
+
+func (d *differ) attributes(prefix string, old, new map[string]*SchemaDump) {
+	for _, name := range unionKeys(old, new) {
+		path := prefix + "." + name
+		o, n := old[name], new[name]
+		switch {
+		case n == nil:
+			// attributes already marked Removed only error out until deleted
+			if o.Removed != "" {
+				d.add(path, ChangeAttributeRemoved, SeverityNonBreaking, nil, nil, "attribute removed, was marked Removed")
+			} else {
+				d.add(path, ChangeAttributeRemoved, SeverityBreaking, nil, nil, "attribute removed")
+			}
+		case o == nil:
+			if n.Required {
+				d.add(path, ChangeAttributeAdded, SeverityBreaking, nil, nil, "required attribute added")
+			} else {
+				d.add(path, ChangeAttributeAdded, SeverityNonBreaking, nil, nil, "attribute added")
+			}
+		default:
+			d.attribute(path, o, n)
+		}
+	}
+}
+
+func (d *differ) attribute(path string, old, new *SchemaDump) {
+	if unresolved(old.Unresolved, new.Unresolved, UnresolvedAll) {
+		return
+	}
+	changed := func(field string, o, n interface{}) bool {
+		return !unresolved(old.Unresolved, new.Unresolved, field) && !reflect.DeepEqual(o, n)
+	}
+
+	if changed("Type", old.Type, new.Type) {
+		d.add(path, ChangeTypeChanged, SeverityBreaking, old.Type.String(), new.Type.String(),
+			"type changed from %s to %s", old.Type, new.Type)
+		// everything else follows from the new type
+		return
+	}
+
+	if changed("Required", old.Required, new.Required) {
+		if new.Required {
+			d.add(path, ChangeOptionalToRequired, SeverityBreaking, nil, nil, "optional attribute became required")
+		} else {
+			d.add(path, ChangeRequiredToOptional, SeverityNonBreaking, nil, nil, "required attribute became optional")
+		}
+	}
+
+	if changed("Computed", old.Computed, new.Computed) {
+		if new.Computed {
+			d.add(path, ChangeComputedAdded, SeverityNonBreaking, nil, nil, "attribute became computed")
+		} else {
+			d.add(path, ChangeComputedRemoved, SeverityBreaking, nil, nil, "attribute is no longer computed")
+		}
+	}
+
+	if changed("ForceNew", old.ForceNew, new.ForceNew) {
+		if new.ForceNew {
+			d.add(path, ChangeForceNewAdded, SeverityBreaking, nil, nil, "ForceNew added, changes now recreate the resource")
+		} else {
+			d.add(path, ChangeForceNewRemoved, SeverityNonBreaking, nil, nil, "ForceNew removed")
+		}
+	}
+
+	if changed("Default", old.Default, new.Default) {
+		d.add(path, ChangeDefaultChanged, SeverityBreaking, old.Default, new.Default,
+			"default changed from %v to %v", old.Default, new.Default)
+	}
+
+	if changed("MaxItems", old.MaxItems, new.MaxItems) {
+		if new.MaxItems != 0 && (old.MaxItems == 0 || new.MaxItems < old.MaxItems) {
+			d.add(path, ChangeMaxItemsTightened, SeverityBreaking, old.MaxItems, new.MaxItems,
+				"MaxItems tightened from %d to %d", old.MaxItems, new.MaxItems)
+		} else {
+			d.add(path, ChangeMaxItemsLoosened, SeverityNonBreaking, old.MaxItems, new.MaxItems,
+				"MaxItems loosened from %d to %d", old.MaxItems, new.MaxItems)
+		}
+	}
+
+	if changed("MinItems", old.MinItems, new.MinItems) {
+		if new.MinItems > old.MinItems {
+			d.add(path, ChangeMinItemsTightened, SeverityBreaking, old.MinItems, new.MinItems,
+				"MinItems tightened from %d to %d", old.MinItems, new.MinItems)
+		} else {
+			d.add(path, ChangeMinItemsLoosened, SeverityNonBreaking, old.MinItems, new.MinItems,
+				"MinItems loosened from %d to %d", old.MinItems, new.MinItems)
+		}
+	}
+
+	if !unresolved(old.Unresolved, new.Unresolved, "ConflictsWith") {
+		if added := missing(new.ConflictsWith, old.ConflictsWith); len(added) > 0 {
+			d.add(path, ChangeConflictsWithAdded, SeverityBreaking, old.ConflictsWith, new.ConflictsWith,
+				"now conflicts with %v", added)
+		}
+	}
+
+	if changed("Deprecated", old.Deprecated, new.Deprecated) && old.Deprecated == "" {
+		d.add(path, ChangeAttributeDeprecated, SeverityNonBreaking, nil, new.Deprecated, "deprecated: %s", new.Deprecated)
+	}
+
+	if changed("Removed", old.Removed, new.Removed) && old.Removed == "" {
+		d.add(path, ChangeAttributeMarkedRemoved, SeverityBreaking, nil, new.Removed, "marked removed: %s", new.Removed)
+	}
+
+	if changed("Sensitive", old.Sensitive, new.Sensitive) {
+		d.add(path, ChangeSensitiveChanged, SeverityNonBreaking, old.Sensitive, new.Sensitive,
+			"Sensitive changed from %t to %t", old.Sensitive, new.Sensitive)
+	}
+
+	// values already in state were produced by the old functions
+	if changed("StateFunc", old.StateFunc, new.StateFunc) {
+		d.add(path, ChangeStateFuncChanged, SeverityStateMigration, funcName(old.StateFunc), funcName(new.StateFunc),
+			"StateFunc changed from %q to %q", funcName(old.StateFunc), funcName(new.StateFunc))
+	}
+	if changed("Set", old.Set, new.Set) {
+		d.add(path, ChangeSetFuncChanged, SeverityStateMigration, funcName(old.Set), funcName(new.Set),
+			"Set hash function changed from %q to %q", funcName(old.Set), funcName(new.Set))
+	}
+
+	if !unresolved(old.Unresolved, new.Unresolved, "Elem") {
+		d.elem(path, old.Elem, new.Elem)
+	}
+}
+
+func (d *differ) elem(path string, old, new interface{}) {
+	switch o := old.(type) {
+	case *ResourceDump:
+		if n, ok := new.(*ResourceDump); ok {
+			d.attributes(path, o.Schema, n.Schema)
+			return
+		}
+	case *SchemaDump:
+		if n, ok := new.(*SchemaDump); ok {
+			d.attribute(path+".*", o, n)
+			return
+		}
+	case nil:
+		if new == nil {
+			return
+		}
+	}
+	d.add(path, ChangeElemChanged, SeverityBreaking, elemKind(old), elemKind(new),
+		"element changed from %s to %s", elemKind(old), elemKind(new))
+}
+
+func elemKind(elem interface{}) string {
+	switch e := elem.(type) {
+	case *ResourceDump:
+		return "block"
+	case *SchemaDump:
+		return e.Type.String()
+	}
+	return "none"
+}
+
+func funcName(f *FuncDump) string {
+	if f == nil {
+		return ""
+	}
+	return f.Name
+}
+
+// A field listed as unresolved on either side can't be compared
+func unresolved(old, new []string, field string) bool {
+	for _, list := range [][]string{old, new} {
+		for _, f := range list {
+			if f == field || f == UnresolvedAll {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Values in a that are not in b
+func missing(a, b []string) []string {
+	var result []string
+	for _, v := range a {
+		found := false
+		for _, w := range b {
+			if v == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func unionKeys(maps ...interface{}) []string {
+	seen := make(map[string]bool)
+	for _, m := range maps {
+		for _, key := range reflect.ValueOf(m).MapKeys() {
+			seen[key.String()] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for key := range seen {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
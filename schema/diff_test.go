@@ -0,0 +1,314 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Kind and severity of a change, the message is left to the changes' String
+type kindOf struct {
+	Path     string
+	Kind     string
+	Severity Severity
+}
+
+func kinds(changes []*Change) []kindOf {
+	var result []kindOf
+	for _, c := range changes {
+		result = append(result, kindOf{c.Path, c.Kind, c.Severity})
+	}
+	return result
+}
+
+func str() *SchemaDump {
+	return &SchemaDump{Type: schema.TypeString, Optional: true}
+}
+
+func with(s *SchemaDump, f func(s *SchemaDump)) *SchemaDump {
+	f(s)
+	return s
+}
+
+func block(attributes map[string]*SchemaDump) *SchemaDump {
+	return &SchemaDump{Type: schema.TypeList, Optional: true, Elem: &ResourceDump{Schema: attributes}}
+}
+
+func TestDiffAttributes(t *testing.T) {
+	cases := []struct {
+		name     string
+		old, new *SchemaDump
+		want     []kindOf
+	}{
+		{"unchanged", str(), str(), nil},
+		{
+			"attribute added", nil, str(),
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeAdded, SeverityNonBreaking}},
+		},
+		{
+			"required attribute added", nil, with(str(), func(s *SchemaDump) { s.Optional, s.Required = false, true }),
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeAdded, SeverityBreaking}},
+		},
+		{
+			"attribute removed", str(), nil,
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeRemoved, SeverityBreaking}},
+		},
+		{
+			"removed after marked Removed", with(str(), func(s *SchemaDump) { s.Removed = "use b" }), nil,
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeRemoved, SeverityNonBreaking}},
+		},
+		{
+			"optional to required", str(), with(str(), func(s *SchemaDump) { s.Optional, s.Required = false, true }),
+			[]kindOf{{"resource.x_thing.a", ChangeOptionalToRequired, SeverityBreaking}},
+		},
+		{
+			"required to optional", with(str(), func(s *SchemaDump) { s.Optional, s.Required = false, true }), str(),
+			[]kindOf{{"resource.x_thing.a", ChangeRequiredToOptional, SeverityNonBreaking}},
+		},
+		{
+			"computed added", str(), with(str(), func(s *SchemaDump) { s.Computed = true }),
+			[]kindOf{{"resource.x_thing.a", ChangeComputedAdded, SeverityNonBreaking}},
+		},
+		{
+			"computed removed", with(str(), func(s *SchemaDump) { s.Computed = true }), str(),
+			[]kindOf{{"resource.x_thing.a", ChangeComputedRemoved, SeverityBreaking}},
+		},
+		{
+			"type changed", str(), with(str(), func(s *SchemaDump) { s.Type, s.Required = schema.TypeInt, true }),
+			// nothing else is reported once the type changed
+			[]kindOf{{"resource.x_thing.a", ChangeTypeChanged, SeverityBreaking}},
+		},
+		{
+			"ForceNew added", str(), with(str(), func(s *SchemaDump) { s.ForceNew = true }),
+			[]kindOf{{"resource.x_thing.a", ChangeForceNewAdded, SeverityBreaking}},
+		},
+		{
+			"ForceNew removed", with(str(), func(s *SchemaDump) { s.ForceNew = true }), str(),
+			[]kindOf{{"resource.x_thing.a", ChangeForceNewRemoved, SeverityNonBreaking}},
+		},
+		{
+			"default changed", with(str(), func(s *SchemaDump) { s.Default = "a" }), with(str(), func(s *SchemaDump) { s.Default = "b" }),
+			[]kindOf{{"resource.x_thing.a", ChangeDefaultChanged, SeverityBreaking}},
+		},
+		{
+			"MaxItems set", block(nil), with(block(nil), func(s *SchemaDump) { s.MaxItems = 1 }),
+			[]kindOf{{"resource.x_thing.a", ChangeMaxItemsTightened, SeverityBreaking}},
+		},
+		{
+			"MaxItems lowered", with(block(nil), func(s *SchemaDump) { s.MaxItems = 3 }), with(block(nil), func(s *SchemaDump) { s.MaxItems = 2 }),
+			[]kindOf{{"resource.x_thing.a", ChangeMaxItemsTightened, SeverityBreaking}},
+		},
+		{
+			"MaxItems unset", with(block(nil), func(s *SchemaDump) { s.MaxItems = 1 }), block(nil),
+			[]kindOf{{"resource.x_thing.a", ChangeMaxItemsLoosened, SeverityNonBreaking}},
+		},
+		{
+			"MinItems raised", block(nil), with(block(nil), func(s *SchemaDump) { s.MinItems = 1 }),
+			[]kindOf{{"resource.x_thing.a", ChangeMinItemsTightened, SeverityBreaking}},
+		},
+		{
+			"MinItems lowered", with(block(nil), func(s *SchemaDump) { s.MinItems = 1 }), block(nil),
+			[]kindOf{{"resource.x_thing.a", ChangeMinItemsLoosened, SeverityNonBreaking}},
+		},
+		{
+			"conflicts added", with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"b"} }),
+			with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"b", "c"} }),
+			[]kindOf{{"resource.x_thing.a", ChangeConflictsWithAdded, SeverityBreaking}},
+		},
+		{
+			"conflicts removed", with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"b"} }), str(),
+			nil,
+		},
+		{
+			"deprecated", str(), with(str(), func(s *SchemaDump) { s.Deprecated = "use b" }),
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeDeprecated, SeverityNonBreaking}},
+		},
+		{
+			"marked removed", str(), with(str(), func(s *SchemaDump) { s.Removed = "use b" }),
+			[]kindOf{{"resource.x_thing.a", ChangeAttributeMarkedRemoved, SeverityBreaking}},
+		},
+		{
+			"sensitive", str(), with(str(), func(s *SchemaDump) { s.Sensitive = true }),
+			[]kindOf{{"resource.x_thing.a", ChangeSensitiveChanged, SeverityNonBreaking}},
+		},
+		{
+			"StateFunc changed", str(), with(str(), func(s *SchemaDump) { s.StateFunc = &FuncDump{Name: "x.normalize"} }),
+			[]kindOf{{"resource.x_thing.a", ChangeStateFuncChanged, SeverityStateMigration}},
+		},
+		{
+			"Set changed", &SchemaDump{Type: schema.TypeSet, Optional: true, Set: &FuncDump{Name: "schema.HashString"}},
+			&SchemaDump{Type: schema.TypeSet, Optional: true, Set: &FuncDump{Name: "x.hash"}},
+			[]kindOf{{"resource.x_thing.a", ChangeSetFuncChanged, SeverityStateMigration}},
+		},
+		{
+			"element type changed",
+			with(block(nil), func(s *SchemaDump) { s.Elem = &SchemaDump{Type: schema.TypeString} }),
+			with(block(nil), func(s *SchemaDump) { s.Elem = &SchemaDump{Type: schema.TypeInt} }),
+			[]kindOf{{"resource.x_thing.a.*", ChangeTypeChanged, SeverityBreaking}},
+		},
+		{
+			"element became a block",
+			with(block(nil), func(s *SchemaDump) { s.Elem = &SchemaDump{Type: schema.TypeString} }),
+			block(nil),
+			[]kindOf{{"resource.x_thing.a", ChangeElemChanged, SeverityBreaking}},
+		},
+		{
+			"nested attribute made required",
+			block(map[string]*SchemaDump{"b": str()}),
+			block(map[string]*SchemaDump{"b": with(str(), func(s *SchemaDump) { s.Optional, s.Required = false, true })}),
+			[]kindOf{{"resource.x_thing.a.b", ChangeOptionalToRequired, SeverityBreaking}},
+		},
+		{
+			"nested block removed",
+			block(map[string]*SchemaDump{"b": block(map[string]*SchemaDump{"c": str()}), "d": str()}),
+			block(map[string]*SchemaDump{"d": str()}),
+			[]kindOf{{"resource.x_thing.a.b", ChangeAttributeRemoved, SeverityBreaking}},
+		},
+		{
+			"unresolved field",
+			with(str(), func(s *SchemaDump) { s.Unresolved = []string{"ForceNew"} }),
+			with(str(), func(s *SchemaDump) { s.ForceNew, s.Computed = true, true }),
+			[]kindOf{{"resource.x_thing.a", ChangeComputedAdded, SeverityNonBreaking}},
+		},
+		{
+			"unresolved schema",
+			str(),
+			&SchemaDump{Type: schema.TypeInt, Required: true, Unresolved: []string{UnresolvedAll}},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old := &ResourceDump{Schema: map[string]*SchemaDump{}}
+			new := &ResourceDump{Schema: map[string]*SchemaDump{}}
+			if c.old != nil {
+				old.Schema["a"] = c.old
+			}
+			if c.new != nil {
+				new.Schema["a"] = c.new
+			}
+			changes := DiffProviders(
+				&ProviderDump{ResourcesMap: map[string]*ResourceDump{"x_thing": old}},
+				&ProviderDump{ResourcesMap: map[string]*ResourceDump{"x_thing": new}},
+			)
+			if got := kinds(changes); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("changes = %v, want %v", got, c.want)
+			}
+			breaking := false
+			for _, k := range c.want {
+				breaking = breaking || k.Severity == SeverityBreaking
+			}
+			if HasBreakingChanges(changes) != breaking {
+				t.Errorf("HasBreakingChanges = %t, want %t", !breaking, breaking)
+			}
+		})
+	}
+}
+
+func TestDiffResources(t *testing.T) {
+	migrate := &FuncDump{Name: "x.migrateThing"}
+	importer := &ImporterDump{Passthrough: true}
+	cases := []struct {
+		name     string
+		old, new *ResourceDump
+		want     []kindOf
+	}{
+		{"unchanged", &ResourceDump{}, &ResourceDump{}, nil},
+		{
+			"resource added", nil, &ResourceDump{},
+			[]kindOf{{"resource.x_thing", ChangeResourceAdded, SeverityNonBreaking}},
+		},
+		{
+			"resource removed", &ResourceDump{}, nil,
+			[]kindOf{{"resource.x_thing", ChangeResourceRemoved, SeverityBreaking}},
+		},
+		{
+			"deprecated", &ResourceDump{}, &ResourceDump{DeprecationMessage: "use x_other"},
+			[]kindOf{{"resource.x_thing", ChangeResourceDeprecated, SeverityNonBreaking}},
+		},
+		{
+			"schema version bumped", &ResourceDump{MigrateState: migrate}, &ResourceDump{SchemaVersion: 1, MigrateState: migrate},
+			[]kindOf{{"resource.x_thing", ChangeSchemaVersionBumped, SeverityStateMigration}},
+		},
+		{
+			"schema version bumped without migration", &ResourceDump{}, &ResourceDump{SchemaVersion: 1},
+			[]kindOf{{"resource.x_thing", ChangeMigrateStateMissing, SeverityBreaking}},
+		},
+		{
+			"schema version lowered", &ResourceDump{SchemaVersion: 2, MigrateState: migrate}, &ResourceDump{SchemaVersion: 1, MigrateState: migrate},
+			[]kindOf{{"resource.x_thing", ChangeSchemaVersionLowered, SeverityBreaking}},
+		},
+		{
+			"importer added", &ResourceDump{}, &ResourceDump{Importer: importer},
+			[]kindOf{{"resource.x_thing", ChangeImporterAdded, SeverityNonBreaking}},
+		},
+		{
+			"importer removed", &ResourceDump{Importer: importer}, &ResourceDump{},
+			[]kindOf{{"resource.x_thing", ChangeImporterRemoved, SeverityBreaking}},
+		},
+		{
+			"unresolved schema version", &ResourceDump{}, &ResourceDump{SchemaVersion: 1, Unresolved: []string{"SchemaVersion"}},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			old := &ProviderDump{ResourcesMap: map[string]*ResourceDump{}}
+			new := &ProviderDump{ResourcesMap: map[string]*ResourceDump{}}
+			if c.old != nil {
+				old.ResourcesMap["x_thing"] = c.old
+			}
+			if c.new != nil {
+				new.ResourcesMap["x_thing"] = c.new
+			}
+			if got := kinds(DiffProviders(old, new)); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("changes = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+// Changes are ordered by path whatever the order of the maps
+func TestDiffProvidersOrder(t *testing.T) {
+	old := &ProviderDump{
+		Schema:         map[string]*SchemaDump{"region": str(), "token": str()},
+		ResourcesMap:   map[string]*ResourceDump{"x_b": {}, "x_c": {}},
+		DataSourcesMap: map[string]*ResourceDump{"x_a": {}},
+	}
+	new := &ProviderDump{
+		Schema:         map[string]*SchemaDump{"region": with(str(), func(s *SchemaDump) { s.Optional, s.Required = false, true })},
+		ResourcesMap:   map[string]*ResourceDump{"x_a": {}, "x_c": {}},
+		DataSourcesMap: map[string]*ResourceDump{},
+	}
+	want := []kindOf{
+		{"provider.region", ChangeOptionalToRequired, SeverityBreaking},
+		{"provider.token", ChangeAttributeRemoved, SeverityBreaking},
+		{"resource.x_a", ChangeResourceAdded, SeverityNonBreaking},
+		{"resource.x_b", ChangeResourceRemoved, SeverityBreaking},
+		{"data.x_a", ChangeResourceRemoved, SeverityBreaking},
+	}
+	if got := kinds(DiffProviders(old, new)); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
+
+func TestDiffProvisioners(t *testing.T) {
+	old := &ProvisionerDump{
+		Schema:     map[string]*SchemaDump{"command": str()},
+		ConnSchema: map[string]*SchemaDump{"host": str()},
+	}
+	new := &ProvisionerDump{
+		Schema:     map[string]*SchemaDump{"command": str(), "interpreter": str()},
+		ConnSchema: map[string]*SchemaDump{"host": with(str(), func(s *SchemaDump) { s.ForceNew = true })},
+	}
+	want := []kindOf{
+		{"provisioner.interpreter", ChangeAttributeAdded, SeverityNonBreaking},
+		{"connection.host", ChangeForceNewAdded, SeverityBreaking},
+	}
+	if got := kinds(DiffProvisioners(old, new)); !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+}
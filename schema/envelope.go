@@ -0,0 +1,79 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// Bumped on incompatible changes to the dump, consumers should refuse
+// a major version they don't know
+const DumpFormatVersion = "1.0"
+
+// Wraps a ProviderDump or ProvisionerDump with where and when it was
+// extracted, exactly one of Schema and Provisioner is set
+type DumpEnvelope struct {
+	FormatVersion string `json:"format_version"`
+	// import path of the plugin, or the file name of the binary for -binary
+	// dumps which also have no commit, tag or SDK version
+	Provider    string           `json:"provider"`
+	Commit      string           `json:"commit,omitempty"`
+	Tag         string           `json:"tag,omitempty"`
+	SDKVersion  string           `json:"sdk_version,omitempty"`
+	ExtractedAt time.Time        `json:"extracted_at"`
+	Schema      *ProviderDump    `json:"schema,omitempty"`
+	Provisioner *ProvisionerDump `json:"provisioner,omitempty"`
+}
+
+// The dump is set by the caller
+func NewDumpEnvelope(provider string, extractedAt time.Time) *DumpEnvelope {
+	return &DumpEnvelope{
+		FormatVersion: DumpFormatVersion,
+		Provider:      provider,
+		ExtractedAt:   extractedAt.UTC(),
+	}
+}
+
+func (e *DumpEnvelope) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(e)
+}
+
+// Reads a dump with or without an envelope, provider dumps from before the
+// envelope are returned with an empty FormatVersion
+func ReadProviderDump(r io.Reader) (*DumpEnvelope, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	if _, ok := raw["format_version"]; !ok {
+		dump := &ProviderDump{}
+		if err := json.Unmarshal(data, dump); err != nil {
+			return nil, err
+		}
+		return &DumpEnvelope{Schema: dump}, nil
+	}
+
+	e := &DumpEnvelope{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return nil, err
+	}
+	major := strings.SplitN(DumpFormatVersion, ".", 2)[0]
+	if strings.SplitN(e.FormatVersion, ".", 2)[0] != major {
+		return nil, fmt.Errorf("unsupported format_version %q, expected %s.x", e.FormatVersion, major)
+	}
+	if e.Schema == nil && e.Provisioner == nil {
+		return nil, errors.New("dump has no schema")
+	}
+	return e, nil
+}
@@ -0,0 +1,127 @@
+package schema
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func testProviderDump() *ProviderDump {
+	return &ProviderDump{
+		Schema: map[string]*SchemaDump{
+			"region": {Type: schema.TypeString, Required: true, Description: "region to use"},
+		},
+		ResourcesMap: map[string]*ResourceDump{
+			"x_thing": {
+				SchemaVersion: 1,
+				MigrateState:  &FuncDump{Name: "x.migrateThing"},
+				Importer:      &ImporterDump{Passthrough: true},
+				Position:      &PositionDump{Filename: "x/resource_thing.go", Line: 12},
+				Schema: map[string]*SchemaDump{
+					"name": {Type: schema.TypeString, Required: true, ForceNew: true},
+					"tags": {Type: schema.TypeSet, Optional: true, Elem: &SchemaDump{Type: schema.TypeString}, Set: &FuncDump{Name: "schema.HashString"}},
+					"rule": {Type: schema.TypeList, Optional: true, MaxItems: 1, Elem: &ResourceDump{Schema: map[string]*SchemaDump{
+						"action": {Type: schema.TypeString, Optional: true, Default: "allow", ValidateFunc: &FuncDump{Name: "validation.StringInSlice", AllowedValues: []string{"allow", "deny"}}},
+					}}},
+					"size": {Type: schema.TypeInt, Computed: true, Unresolved: []string{"Default"}, Position: &PositionDump{Filename: "x/resource_thing.go", Line: 20}},
+				},
+			},
+		},
+		DataSourcesMap: map[string]*ResourceDump{
+			"x_thing": {Schema: map[string]*SchemaDump{"name": {Type: schema.TypeString, Computed: true}}},
+		},
+	}
+}
+
+func TestReadProviderDump(t *testing.T) {
+	e := NewDumpEnvelope("github.com/terraform-providers/terraform-provider-x", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Commit = "0b5c4a2e1d3c1b6a9d1f2e7c8a9b0c1d2e3f4a5b"
+	e.SDKVersion = "v0.11.11"
+	e.Schema = testProviderDump()
+	var buf bytes.Buffer
+	if err := e.ToJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadProviderDump(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, e) {
+		t.Errorf("ReadProviderDump = %+v, want %+v", read, e)
+	}
+}
+
+func TestReadProviderDumpProvisioner(t *testing.T) {
+	e := NewDumpEnvelope("github.com/x/terraform-provisioner-x", time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	e.Provisioner = &ProvisionerDump{
+		Schema:     map[string]*SchemaDump{"command": {Type: schema.TypeString, Required: true}},
+		ConnSchema: map[string]*SchemaDump{"host": {Type: schema.TypeString, Optional: true}},
+	}
+	var buf bytes.Buffer
+	if err := e.ToJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadProviderDump(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, e) {
+		t.Errorf("ReadProviderDump = %+v, want %+v", read, e)
+	}
+}
+
+// dumps from before the envelope are plain provider dumps
+func TestReadProviderDumpWithoutEnvelope(t *testing.T) {
+	dump := testProviderDump()
+	var buf bytes.Buffer
+	if err := dump.ToJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadProviderDump(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if read.FormatVersion != "" || read.Provisioner != nil {
+		t.Errorf("ReadProviderDump = %+v, want only a schema", read)
+	}
+	if !reflect.DeepEqual(read.Schema, dump) {
+		t.Errorf("ReadProviderDump schema = %+v, want %+v", read.Schema, dump)
+	}
+}
+
+func TestReadProviderDumpErrors(t *testing.T) {
+	cases := []struct {
+		name, json, err string
+	}{
+		{"invalid", `{"format_version": "1.0",`, "unexpected end of JSON input"},
+		{"not an object", `[]`, "cannot unmarshal array"},
+		{"newer major", `{"format_version": "2.0", "provider": "x", "schema": {}}`, `unsupported format_version "2.0", expected 1.x`},
+		{"no schema", `{"format_version": "1.0", "provider": "x"}`, "dump has no schema"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := ReadProviderDump(strings.NewReader(c.json))
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("ReadProviderDump error = %v, want %q", err, c.err)
+			}
+		})
+	}
+}
+
+// a newer minor version only adds fields
+func TestReadProviderDumpMinorVersion(t *testing.T) {
+	e, err := ReadProviderDump(strings.NewReader(`{"format_version": "1.7", "provider": "x", "unknown": true, "schema": {"Schema": {}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.FormatVersion != "1.7" || e.Schema == nil {
+		t.Errorf("ReadProviderDump = %+v", e)
+	}
+}
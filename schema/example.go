@@ -0,0 +1,197 @@
+package schema
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+// Configuration language versions examples can be written for
+const (
+	Syntax011 = "0.11"
+	Syntax012 = "0.12"
+)
+
+type ExampleOptions struct {
+	// set Optional arguments as well as Required ones
+	Optional bool
+	// Syntax011 or Syntax012, empty is Syntax012
+	Syntax string
+}
+
+// Minimal configuration of a resource named example
+func ExampleResource(name string, r *ResourceDump, opts ExampleOptions) string {
+	return exampleBlock(fmt.Sprintf("resource %q \"example\"", name), r.Schema, opts)
+}
+
+func ExampleDataSource(name string, r *ResourceDump, opts ExampleOptions) string {
+	return exampleBlock(fmt.Sprintf("data %q \"example\"", name), r.Schema, opts)
+}
+
+func ExampleProvider(name string, schemas map[string]*SchemaDump, opts ExampleOptions) string {
+	return exampleBlock(fmt.Sprintf("provider %q", name), schemas, opts)
+}
+
+// Provisioners are shown inside a null_resource
+func ExampleProvisioner(name string, schemas map[string]*SchemaDump, opts ExampleOptions) string {
+	var b bytes.Buffer
+	b.WriteString("resource \"null_resource\" \"example\" {\n")
+	fmt.Fprintf(&b, "  provisioner %q {\n", name)
+	writeExampleArguments(&b, schemas, "    ", opts)
+	b.WriteString("  }\n}\n")
+	return b.String()
+}
+
+// Whether the attribute is written as a nested block
+func (s *SchemaDump) IsBlock() bool {
+	_, ok := s.Elem.(*ResourceDump)
+	return ok && s.Type != schema.TypeMap
+}
+
+func exampleBlock(header string, schemas map[string]*SchemaDump, opts ExampleOptions) string {
+	var b bytes.Buffer
+	b.WriteString(header + " {\n")
+	writeExampleArguments(&b, schemas, "  ", opts)
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Attributes come first aligned on =, the way terraform fmt writes them,
+// blocks follow. Blocks are repeated MinItems times.
+func writeExampleArguments(b *bytes.Buffer, schemas map[string]*SchemaDump, indent string, opts ExampleOptions) {
+	var attributes, blocks []string
+	width := 0
+	for _, key := range exampleArguments(schemas, opts) {
+		s := schemas[key]
+		// 0.11 writes maps like blocks, 0.12 rejects that
+		if s.IsBlock() || (s.Type == schema.TypeMap && opts.Syntax == Syntax011) {
+			blocks = append(blocks, key)
+			continue
+		}
+		attributes = append(attributes, key)
+		if len(key) > width {
+			width = len(key)
+		}
+	}
+
+	for _, key := range attributes {
+		fmt.Fprintf(b, "%s%-*s = %s\n", indent, width, key, exampleValue(schemas[key], indent))
+	}
+	for _, key := range blocks {
+		s := schemas[key]
+		count := 1
+		if s.MinItems > 1 {
+			count = s.MinItems
+		}
+		for i := 0; i < count; i++ {
+			if !strings.HasSuffix(b.String(), "{\n") {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "%s%s {\n", indent, key)
+			if elem, ok := s.Elem.(*ResourceDump); ok && s.Type != schema.TypeMap {
+				writeExampleArguments(b, elem.Schema, indent+"  ", opts)
+			} else {
+				fmt.Fprintf(b, "%s  key = %s\n", indent, exampleElemValue(s, indent+"  "))
+			}
+			fmt.Fprintf(b, "%s}\n", indent)
+		}
+	}
+}
+
+// Required arguments, and with opts.Optional the optional ones that are
+// neither deprecated nor conflicting with an argument already set
+func exampleArguments(schemas map[string]*SchemaDump, opts ExampleOptions) []string {
+	var keys []string
+	for key, s := range schemas {
+		if s.Required {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	if !opts.Optional {
+		return keys
+	}
+
+	set := make(map[string]bool)
+	for _, key := range keys {
+		set[key] = true
+	}
+	var optional []string
+	for key, s := range schemas {
+		if s.Optional && s.Deprecated == "" && s.Removed == "" {
+			optional = append(optional, key)
+		}
+	}
+	sort.Strings(optional)
+	for _, key := range optional {
+		if conflicts(schemas, set, key) {
+			continue
+		}
+		set[key] = true
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func conflicts(schemas map[string]*SchemaDump, set map[string]bool, key string) bool {
+	for _, c := range schemas[key].ConflictsWith {
+		if set[c] {
+			return true
+		}
+	}
+	for other := range set {
+		for _, c := range schemas[other].ConflictsWith {
+			if c == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// The Default or first allowed value when there is one, a placeholder of the
+// right type otherwise
+func exampleValue(s *SchemaDump, indent string) string {
+	if s.Default != nil {
+		switch s.Type {
+		case schema.TypeString:
+			return fmt.Sprintf("%q", fmt.Sprint(s.Default))
+		case schema.TypeBool, schema.TypeInt, schema.TypeFloat:
+			// numbers decoded from a dump are float64, fmt would write 1e+06
+			if f, ok := s.Default.(float64); ok {
+				return strconv.FormatFloat(f, 'f', -1, 64)
+			}
+			return fmt.Sprint(s.Default)
+		}
+	}
+	if s.ValidateFunc != nil && len(s.ValidateFunc.AllowedValues) > 0 {
+		return fmt.Sprintf("%q", s.ValidateFunc.AllowedValues[0])
+	}
+
+	switch s.Type {
+	case schema.TypeBool:
+		return "true"
+	case schema.TypeInt:
+		return "1"
+	case schema.TypeFloat:
+		return "1.5"
+	case schema.TypeList, schema.TypeSet:
+		return "[" + exampleElemValue(s, indent) + "]"
+	case schema.TypeMap:
+		return fmt.Sprintf("{\n%s  key = %s\n%s}", indent, exampleElemValue(s, indent+"  "), indent)
+	}
+	return `"example"`
+}
+
+// Elements of lists, sets and maps are strings unless Elem says otherwise
+func exampleElemValue(s *SchemaDump, indent string) string {
+	if elem, ok := s.Elem.(*SchemaDump); ok {
+		return exampleValue(elem, indent)
+	}
+	return `"example"`
+}
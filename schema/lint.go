@@ -0,0 +1,221 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+const (
+	FindingError   = "error"
+	FindingWarning = "warning"
+)
+
+// Lint rules run over a ProviderDump
+const (
+	RuleInternalValidate     = "internal-validate"
+	RuleIDAttribute          = "id-attribute"
+	RuleComputedWithDefault  = "computed-with-default"
+	RuleRequiredWithDefault  = "required-with-default"
+	RuleConflictsWithUnknown = "conflicts-with-unknown"
+	RuleMapWithResourceElem  = "map-with-resource-elem"
+	RuleMissingDescription   = "missing-description"
+)
+
+// What the dump being linted carries, rules that can't apply are skipped
+type LintOptions struct {
+	// descriptions are part of the dump, binary dumps don't have them
+	Descriptions bool
+	// InternalValidate ran over the provider, rules it already checks are
+	// not repeated
+	InternalValidate bool
+}
+
+// A single problem found in a provider's schema
+type Finding struct {
+	// Dotted location ex: resource.aws_instance.ebs_block_device.volume_size
+	Path     string
+	Rule     string
+	Severity string
+	Message  string
+}
+
+func (f *Finding) String() string {
+	return fmt.Sprintf("[%s] %s (%s): %s", f.Severity, f.Path, f.Rule, f.Message)
+}
+
+type ValidationReport struct {
+	Findings []*Finding
+}
+
+func (r *ValidationReport) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(r)
+}
+
+func (r *ValidationReport) HasErrors() bool {
+	for _, f := range r.Findings {
+		if f.Severity == FindingError {
+			return true
+		}
+	}
+	return false
+}
+
+// Runs InternalValidate over the provider and lints its dump, this needs the
+// compiled provider so it is only available to the dumper
+func NewValidationReport(providerFunc func() terraform.ResourceProvider) *ValidationReport {
+	i := providerFunc().(*schema.Provider)
+	r := &ValidationReport{}
+	r.Findings = internalValidateFindings("provider", i.InternalValidate())
+	r.Findings = append(r.Findings, LintProvider(NewProviderDump(providerFunc), LintOptions{Descriptions: true, InternalValidate: true})...)
+	return r
+}
+
+// Same as NewValidationReport for a provisioner
+func NewProvisionerValidationReport(provisionerFunc func() terraform.ResourceProvisioner) *ValidationReport {
+	i := provisionerFunc().(*schema.Provisioner)
+	r := &ValidationReport{}
+	r.Findings = internalValidateFindings("provisioner", i.InternalValidate())
+	r.Findings = append(r.Findings, LintProvisioner(NewProvisionerDump(provisionerFunc), LintOptions{Descriptions: true, InternalValidate: true})...)
+	return r
+}
+
+func internalValidateFindings(path string, err error) []*Finding {
+	if err == nil {
+		return nil
+	}
+	errs := []error{err}
+	// InternalValidate collects its errors in a multierror
+	if wrapped, ok := err.(interface{ WrappedErrors() []error }); ok {
+		errs = wrapped.WrappedErrors()
+	}
+	var findings []*Finding
+	for _, e := range errs {
+		findings = append(findings, &Finding{
+			Path:     path,
+			Rule:     RuleInternalValidate,
+			Severity: FindingError,
+			Message:  e.Error(),
+		})
+	}
+	return findings
+}
+
+// Checks a dump for common schema mistakes, fields static extraction could
+// not resolve are skipped
+func LintProvider(p *ProviderDump, opts LintOptions) []*Finding {
+	l := &linter{opts: opts}
+	l.attributes("provider", p.Schema, p.Schema)
+	for _, name := range unionKeys(p.ResourcesMap) {
+		l.resource("resource."+name, p.ResourcesMap[name])
+	}
+	for _, name := range unionKeys(p.DataSourcesMap) {
+		l.resource("data."+name, p.DataSourcesMap[name])
+	}
+	return l.findings
+}
+
+// Same as LintProvider for a provisioner, the connection block is linted
+// under the connection path
+func LintProvisioner(p *ProvisionerDump, opts LintOptions) []*Finding {
+	l := &linter{opts: opts}
+	l.attributes("provisioner", p.Schema, p.Schema)
+	l.attributes("connection", p.ConnSchema, p.ConnSchema)
+	return l.findings
+}
+
+type linter struct {
+	opts     LintOptions
+	findings []*Finding
+}
+
+func (l *linter) add(path, rule, severity, format string, a ...interface{}) {
+	l.findings = append(l.findings, &Finding{
+		Path:     path,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, a...),
+	})
+}
+
+func (l *linter) resource(path string, r *ResourceDump) {
+	if _, ok := r.Schema["id"]; ok {
+		l.add(path+".id", RuleIDAttribute, FindingError, "id is set by Terraform, it must not be declared")
+	}
+	l.attributes(path, r.Schema, r.Schema)
+}
+
+// root is the top level schema of the resource, ConflictsWith keys are
+// relative to it
+func (l *linter) attributes(prefix string, schemas, root map[string]*SchemaDump) {
+	for _, name := range unionKeys(schemas) {
+		path := prefix + "." + name
+		s := schemas[name]
+		if unresolved(s.Unresolved, nil, UnresolvedAll) {
+			continue
+		}
+		known := func(field string) bool {
+			return !unresolved(s.Unresolved, nil, field)
+		}
+
+		// InternalValidate rejects both already
+		if !l.opts.InternalValidate {
+			if s.Computed && s.Default != nil && known("Computed") && known("Default") {
+				l.add(path, RuleComputedWithDefault, FindingError, "Default must be nil when Computed")
+			}
+
+			if s.Required && s.Default != nil && known("Required") && known("Default") {
+				l.add(path, RuleRequiredWithDefault, FindingError, "Default has no effect on a Required attribute")
+			}
+		}
+
+		if known("ConflictsWith") {
+			for _, key := range s.ConflictsWith {
+				if !hasKey(root, key) {
+					l.add(path, RuleConflictsWithUnknown, FindingError, "ConflictsWith names unknown key %q", key)
+				}
+			}
+		}
+
+		_, resourceElem := s.Elem.(*ResourceDump)
+		if s.Type == schema.TypeMap && resourceElem && known("Type") && known("Elem") {
+			l.add(path, RuleMapWithResourceElem, FindingError, "TypeMap can't have a *schema.Resource Elem")
+		}
+
+		if l.opts.Descriptions && s.Description == "" && known("Description") {
+			l.add(path, RuleMissingDescription, FindingWarning, "Description is empty")
+		}
+
+		if elem, ok := s.Elem.(*ResourceDump); ok {
+			l.attributes(path, elem.Schema, root)
+		}
+	}
+}
+
+// Walks a key like ebs_block_device.0.volume_size, list indexes are skipped
+func hasKey(schemas map[string]*SchemaDump, key string) bool {
+	for _, part := range strings.Split(key, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			continue
+		}
+		if schemas == nil {
+			return false
+		}
+		s, ok := schemas[part]
+		if !ok {
+			return false
+		}
+		schemas = nil
+		if elem, ok := s.Elem.(*ResourceDump); ok {
+			schemas = elem.Schema
+		}
+	}
+	return true
+}
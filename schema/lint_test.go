@@ -0,0 +1,171 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestLintProvider(t *testing.T) {
+	described := func(s *SchemaDump) *SchemaDump {
+		s.Description = "described"
+		return s
+	}
+	cases := []struct {
+		name    string
+		schemas map[string]*SchemaDump
+		opts    LintOptions
+		// path and rule of each finding
+		want [][2]string
+	}{
+		{
+			"id attribute",
+			map[string]*SchemaDump{"id": str()},
+			LintOptions{},
+			[][2]string{{"resource.x_thing.id", RuleIDAttribute}},
+		},
+		{
+			"no id attribute",
+			map[string]*SchemaDump{"name": str()},
+			LintOptions{},
+			nil,
+		},
+		{
+			"computed with default",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.Computed, s.Default = true, "a" })},
+			LintOptions{},
+			[][2]string{{"resource.x_thing.name", RuleComputedWithDefault}},
+		},
+		{
+			"computed without default",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.Computed = true })},
+			LintOptions{},
+			nil,
+		},
+		{
+			"computed with default checked by InternalValidate",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.Computed, s.Default = true, "a" })},
+			LintOptions{InternalValidate: true},
+			nil,
+		},
+		{
+			"computed with unresolved default",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) {
+				s.Computed, s.Default, s.Unresolved = true, "a", []string{"Default"}
+			})},
+			LintOptions{},
+			nil,
+		},
+		{
+			"required with default",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.Optional, s.Required, s.Default = false, true, "a" })},
+			LintOptions{},
+			[][2]string{{"resource.x_thing.name", RuleRequiredWithDefault}},
+		},
+		{
+			"optional with default",
+			map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.Default = "a" })},
+			LintOptions{},
+			nil,
+		},
+		{
+			"conflicts with unknown key",
+			map[string]*SchemaDump{
+				"name":  with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"other"} }),
+				"block": block(map[string]*SchemaDump{"a": with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"block.0.b"} })}),
+			},
+			LintOptions{},
+			[][2]string{
+				{"resource.x_thing.block.a", RuleConflictsWithUnknown},
+				{"resource.x_thing.name", RuleConflictsWithUnknown},
+			},
+		},
+		{
+			"conflicts with known keys",
+			map[string]*SchemaDump{
+				"name":  with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"block.0.a"} }),
+				"block": block(map[string]*SchemaDump{"a": with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"name"} })}),
+			},
+			LintOptions{},
+			nil,
+		},
+		{
+			"map with resource elem",
+			map[string]*SchemaDump{"tags": with(block(nil), func(s *SchemaDump) { s.Type = schema.TypeMap })},
+			LintOptions{},
+			[][2]string{{"resource.x_thing.tags", RuleMapWithResourceElem}},
+		},
+		{
+			"map with schema elem",
+			map[string]*SchemaDump{"tags": {Type: schema.TypeMap, Optional: true, Elem: &SchemaDump{Type: schema.TypeString}}},
+			LintOptions{},
+			nil,
+		},
+		{
+			"missing description",
+			map[string]*SchemaDump{
+				"name":  described(str()),
+				"block": described(block(map[string]*SchemaDump{"a": str()})),
+			},
+			LintOptions{Descriptions: true},
+			[][2]string{{"resource.x_thing.block.a", RuleMissingDescription}},
+		},
+		{
+			"descriptions",
+			map[string]*SchemaDump{
+				"name":  described(str()),
+				"block": described(block(map[string]*SchemaDump{"a": described(str())})),
+			},
+			LintOptions{Descriptions: true},
+			nil,
+		},
+		{
+			"descriptions not dumped",
+			map[string]*SchemaDump{"name": str()},
+			LintOptions{},
+			nil,
+		},
+		{
+			"unresolved schema",
+			map[string]*SchemaDump{"name": {Required: true, Computed: true, Default: "a", Unresolved: []string{UnresolvedAll}}},
+			LintOptions{Descriptions: true},
+			nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := &ProviderDump{ResourcesMap: map[string]*ResourceDump{"x_thing": {Schema: c.schemas}}}
+			var got [][2]string
+			for _, f := range LintProvider(p, c.opts) {
+				got = append(got, [2]string{f.Path, f.Rule})
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("findings = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+// Provider attributes, data sources and provisioners are linted the same way
+func TestLintPaths(t *testing.T) {
+	bad := func() map[string]*SchemaDump {
+		return map[string]*SchemaDump{"name": with(str(), func(s *SchemaDump) { s.ConflictsWith = []string{"other"} })}
+	}
+	p := &ProviderDump{
+		Schema:         bad(),
+		DataSourcesMap: map[string]*ResourceDump{"x_thing": {Schema: bad()}},
+	}
+	var got []string
+	for _, f := range LintProvider(p, LintOptions{}) {
+		got = append(got, f.Path)
+	}
+	for _, f := range LintProvisioner(&ProvisionerDump{Schema: bad(), ConnSchema: bad()}, LintOptions{}) {
+		got = append(got, f.Path)
+	}
+	want := []string{"provider.name", "data.x_thing.name", "provisioner.name", "connection.name"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,967 @@
+package static
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/appilon/tfplugin/schema"
+	tfschema "github.com/hashicorp/terraform/helper/schema"
+)
+
+// how many variables/calls are followed to find a value
+const maxDepth = 16
+
+var valueTypes = map[string]tfschema.ValueType{
+	"TypeBool":   tfschema.TypeBool,
+	"TypeInt":    tfschema.TypeInt,
+	"TypeFloat":  tfschema.TypeFloat,
+	"TypeString": tfschema.TypeString,
+	"TypeList":   tfschema.TypeList,
+	"TypeMap":    tfschema.TypeMap,
+	"TypeSet":    tfschema.TypeSet,
+}
+
+// Extracts the schema of the provider exported by packageName without compiling
+// the provider. The schema.Provider, Resource and Schema composite literals are
+// followed from the Provider function through variables and function calls.
+// Values that can't be evaluated are listed in Unresolved.
+func Extract(providerPath, importPath, packageName string) (*schema.ProviderDump, error) {
+	l, lit, sc, err := loadPlugin(providerPath, importPath, packageName, "Provider")
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &schema.ProviderDump{}
+	dump.Schema = make(map[string]*schema.SchemaDump)
+	dump.ResourcesMap = make(map[string]*schema.ResourceDump)
+	dump.DataSourcesMap = make(map[string]*schema.ResourceDump)
+	for _, f := range fields(lit) {
+		name, value := f.name, f.value
+		ok := true
+		switch name {
+		case "Schema":
+			ok = l.schemaMap(value, sc, dump.Schema)
+		case "ResourcesMap":
+			ok = l.resourceMap(value, sc, dump.ResourcesMap)
+		case "DataSourcesMap":
+			ok = l.resourceMap(value, sc, dump.DataSourcesMap)
+		}
+		if !ok {
+			dump.Unresolved = append(dump.Unresolved, name)
+		}
+	}
+
+	return dump, nil
+}
+
+// Same as Extract for the provisioner exported by packageName, the
+// schema.Provisioner is followed from the Provisioner function
+func ExtractProvisioner(provisionerPath, importPath, packageName string) (*schema.ProvisionerDump, error) {
+	l, lit, sc, err := loadPlugin(provisionerPath, importPath, packageName, "Provisioner")
+	if err != nil {
+		return nil, err
+	}
+
+	dump := &schema.ProvisionerDump{}
+	dump.Schema = make(map[string]*schema.SchemaDump)
+	dump.ConnSchema = make(map[string]*schema.SchemaDump)
+	for _, f := range fields(lit) {
+		name, value := f.name, f.value
+		ok := true
+		switch name {
+		case "Schema":
+			ok = l.schemaMap(value, sc, dump.Schema)
+		case "ConnSchema":
+			ok = l.schemaMap(value, sc, dump.ConnSchema)
+		}
+		if !ok {
+			dump.Unresolved = append(dump.Unresolved, name)
+		}
+	}
+
+	return dump, nil
+}
+
+// Finds the composite literal returned by the plugin's exported function,
+// Provider or Provisioner
+func loadPlugin(pluginPath, importPath, packageName, funcName string) (*loader, *ast.CompositeLit, scope, error) {
+	l := &loader{
+		fset:       token.NewFileSet(),
+		root:       pluginPath,
+		importPath: importPath,
+		packages:   make(map[string]*pkg),
+		std:        importer.Default(),
+	}
+
+	p, err := l.load(path.Join(importPath, packageName))
+	if err != nil {
+		return nil, nil, scope{}, err
+	}
+
+	fn, exists := p.funcs[funcName]
+	if !exists {
+		return nil, nil, scope{}, fmt.Errorf("no %s function in %s", funcName, p.path)
+	}
+
+	lit, sc := l.compositeLit(returned(fn), scope{p, fn}, 0)
+	if lit == nil {
+		return nil, nil, scope{}, fmt.Errorf("could not find the schema.%s returned by %s.%s", funcName, p.name, funcName)
+	}
+
+	return l, lit, sc, nil
+}
+
+type loader struct {
+	fset *token.FileSet
+	// provider checkout and its import path, packages within are parsed
+	root       string
+	importPath string
+	packages   map[string]*pkg
+	std        types.Importer
+}
+
+type pkg struct {
+	name  string
+	path  string
+	funcs map[string]*ast.FuncDecl
+	// values assigned at the declaration of variables and constants
+	decls map[token.Pos]*decl
+	// changes to variables after their declaration, by the position of the
+	// variable
+	edits map[token.Pos][]*mapEdit
+	info  *types.Info
+	types *types.Package
+}
+
+type decl struct {
+	value ast.Expr
+	fn    *ast.FuncDecl
+}
+
+// m[key] = value or delete(m, key) on a variable holding a map. Changes
+// that can't be followed, like passing the map to a function, have no key.
+type mapEdit struct {
+	pos   token.Pos
+	key   ast.Expr
+	value ast.Expr
+	sc    scope
+}
+
+// Expressions are evaluated within a package and possibly a function
+type scope struct {
+	pkg *pkg
+	fn  *ast.FuncDecl
+}
+
+// Parses and type checks a package of the provider, errors from type checking
+// are expected as dependencies outside of the provider are not loaded
+func (l *loader) load(importPath string) (*pkg, error) {
+	if p, exists := l.packages[importPath]; exists {
+		return p, nil
+	}
+
+	dir := filepath.Join(l.root, filepath.FromSlash(strings.TrimPrefix(importPath, l.importPath)))
+	parsed, err := parser.ParseDir(l.fset, dir, func(info os.FileInfo) bool {
+		if strings.HasSuffix(info.Name(), "_test.go") {
+			return false
+		}
+		match, err := build.Default.MatchFile(dir, info.Name())
+		return err == nil && match
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(parsed))
+	}
+
+	p := &pkg{
+		path:  importPath,
+		funcs: make(map[string]*ast.FuncDecl),
+		decls: make(map[token.Pos]*decl),
+		edits: make(map[token.Pos][]*mapEdit),
+		info: &types.Info{
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Defs:  make(map[*ast.Ident]types.Object),
+			Uses:  make(map[*ast.Ident]types.Object),
+		},
+	}
+	// registered before type checking, imports can't be cyclic
+	l.packages[importPath] = p
+
+	var files []*ast.File
+	for name, astPkg := range parsed {
+		p.name = name
+		for _, file := range astPkg.Files {
+			files = append(files, file)
+		}
+	}
+
+	conf := &types.Config{
+		Importer: l,
+		Error:    func(error) {},
+	}
+	p.types, _ = conf.Check(importPath, l.fset, files, p.info)
+
+	for _, file := range files {
+		p.index(file)
+	}
+
+	return p, nil
+}
+
+// Implements types.Importer, packages of the provider are loaded from source,
+// the standard library from export data and anything else is left empty
+func (l *loader) Import(importPath string) (*types.Package, error) {
+	if importPath == l.importPath || strings.HasPrefix(importPath, l.importPath+"/") {
+		p, err := l.load(importPath)
+		if err == nil && p.types != nil {
+			return p.types, nil
+		}
+	} else if !strings.Contains(strings.Split(importPath, "/")[0], ".") {
+		if p, err := l.std.Import(importPath); err == nil {
+			return p, nil
+		}
+	}
+
+	name := path.Base(importPath)
+	if i := strings.IndexAny(name, ".-"); i > -1 {
+		name = name[:i]
+	}
+	p := types.NewPackage(importPath, name)
+	p.MarkComplete()
+	return p, nil
+}
+
+func (p *pkg) index(file *ast.File) {
+	for _, d := range file.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				p.funcs[d.Name.Name] = d
+			}
+			if d.Body != nil {
+				p.indexBody(d)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if spec, ok := spec.(*ast.ValueSpec); ok {
+					p.indexValueSpec(spec, nil)
+				}
+			}
+		}
+	}
+}
+
+func (p *pkg) indexValueSpec(spec *ast.ValueSpec, fn *ast.FuncDecl) {
+	if len(spec.Names) != len(spec.Values) {
+		return
+	}
+	for i, name := range spec.Names {
+		p.decls[name.Pos()] = &decl{spec.Values[i], fn}
+	}
+}
+
+func (p *pkg) indexBody(fn *ast.FuncDecl) {
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.ValueSpec:
+			p.indexValueSpec(n, fn)
+		case *ast.CallExpr:
+			p.indexCall(n, fn)
+		case *ast.AssignStmt:
+			for i, lhs := range n.Lhs {
+				var value ast.Expr
+				if len(n.Lhs) == len(n.Rhs) && n.Tok == token.ASSIGN {
+					value = n.Rhs[i]
+				}
+				p.indexEdit(lhs, value, fn)
+			}
+			if len(n.Lhs) != len(n.Rhs) {
+				return true
+			}
+			for i, lhs := range n.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				// the first assignment of variables declared without a value
+				if obj := p.info.Defs[ident]; obj != nil {
+					p.decls[obj.Pos()] = &decl{n.Rhs[i], fn}
+				} else if obj := p.info.Uses[ident]; obj != nil {
+					if _, exists := p.decls[obj.Pos()]; !exists {
+						p.decls[obj.Pos()] = &decl{n.Rhs[i], fn}
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// Records an assignment to an element of a map variable, anything but
+// m[key] = value is a change that can't be followed
+func (p *pkg) indexEdit(lhs ast.Expr, value ast.Expr, fn *ast.FuncDecl) {
+	index, ok := lhs.(*ast.IndexExpr)
+	if !ok {
+		// m[key].Optional = true
+		if sel, ok := lhs.(*ast.SelectorExpr); ok {
+			p.indexEdit(sel.X, nil, fn)
+		}
+		return
+	}
+	ident, ok := index.X.(*ast.Ident)
+	if !ok {
+		// m[a][b] = value
+		p.indexEdit(index.X, nil, fn)
+		return
+	}
+	obj := p.info.Uses[ident]
+	if obj == nil {
+		return
+	}
+	edit := &mapEdit{pos: lhs.Pos(), sc: scope{p, fn}}
+	if value != nil {
+		edit.key, edit.value = index.Index, value
+	}
+	p.edits[obj.Pos()] = append(p.edits[obj.Pos()], edit)
+}
+
+// delete(m, key) is recorded as an edit of m, variables passed to any other
+// function may be changed by it
+func (p *pkg) indexCall(call *ast.CallExpr, fn *ast.FuncDecl) {
+	builtin := ""
+	if ident, ok := call.Fun.(*ast.Ident); ok {
+		if b, ok := p.info.Uses[ident].(*types.Builtin); ok {
+			builtin = b.Name()
+		}
+	}
+	if builtin != "" && builtin != "delete" {
+		return
+	}
+	for i, arg := range call.Args {
+		if u, ok := arg.(*ast.UnaryExpr); ok && u.Op == token.AND {
+			arg = u.X
+		}
+		ident, ok := arg.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		obj, ok := p.info.Uses[ident].(*types.Var)
+		if !ok {
+			continue
+		}
+		edit := &mapEdit{pos: call.Pos(), sc: scope{p, fn}}
+		if builtin == "delete" {
+			if i != 0 || len(call.Args) != 2 {
+				continue
+			}
+			edit.key = call.Args[1]
+		}
+		p.edits[obj.Pos()] = append(p.edits[obj.Pos()], edit)
+	}
+}
+
+// The expression returned by a function
+func returned(fn *ast.FuncDecl) ast.Expr {
+	var result ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if result != nil {
+			return false
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.ReturnStmt:
+			if len(n.Results) > 0 {
+				result = n.Results[0]
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// Follows variables and function calls to the value of expr
+func (l *loader) resolve(expr ast.Expr, sc scope, depth int) (ast.Expr, scope) {
+	for ; depth < maxDepth && expr != nil; depth++ {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.UnaryExpr:
+			if e.Op != token.AND {
+				return expr, sc
+			}
+			expr = e.X
+		case *ast.Ident, *ast.SelectorExpr:
+			d, dsc := l.declOf(e, sc)
+			if d == nil {
+				return expr, sc
+			}
+			expr, sc = d.value, dsc
+		case *ast.CallExpr:
+			fn, p := l.funcOf(e.Fun, sc)
+			if fn == nil {
+				return expr, sc
+			}
+			expr, sc = returned(fn), scope{p, fn}
+		default:
+			return expr, sc
+		}
+	}
+	return expr, sc
+}
+
+// The object an identifier or qualified identifier refers to
+func (l *loader) objectOf(expr ast.Expr, sc scope) types.Object {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return sc.pkg.info.Uses[e]
+	case *ast.SelectorExpr:
+		return sc.pkg.info.Uses[e.Sel]
+	}
+	return nil
+}
+
+func (l *loader) declOf(expr ast.Expr, sc scope) (*decl, scope) {
+	obj := l.objectOf(expr, sc)
+	if obj == nil || obj.Pkg() == nil {
+		return nil, sc
+	}
+	p, exists := l.packages[obj.Pkg().Path()]
+	if !exists {
+		return nil, sc
+	}
+	d, exists := p.decls[obj.Pos()]
+	if !exists {
+		return nil, sc
+	}
+	return d, scope{p, d.fn}
+}
+
+func (l *loader) funcOf(expr ast.Expr, sc scope) (*ast.FuncDecl, *pkg) {
+	obj, ok := l.objectOf(expr, sc).(*types.Func)
+	if !ok || obj.Pkg() == nil {
+		return nil, nil
+	}
+	p, exists := l.packages[obj.Pkg().Path()]
+	if !exists {
+		return nil, nil
+	}
+	fn, exists := p.funcs[obj.Name()]
+	if !exists || fn.Body == nil {
+		return nil, nil
+	}
+	return fn, p
+}
+
+func (l *loader) compositeLit(expr ast.Expr, sc scope, depth int) (*ast.CompositeLit, scope) {
+	expr, sc = l.resolve(expr, sc, depth)
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit, sc
+}
+
+// Like compositeLit for a map, the edits of the variables followed to the
+// literal are returned in source order
+func (l *loader) mapLit(expr ast.Expr, sc scope) (*ast.CompositeLit, scope, []*mapEdit) {
+	var edits []*mapEdit
+	for depth := 0; depth < maxDepth; depth++ {
+		if obj := l.objectOf(expr, sc); obj != nil {
+			for _, p := range l.packages {
+				edits = append(edits, p.edits[obj.Pos()]...)
+			}
+		}
+		resolved, rsc := l.resolve(expr, sc, maxDepth-1)
+		if resolved == expr {
+			break
+		}
+		expr, sc = resolved, rsc
+	}
+	sort.Slice(edits, func(i, j int) bool {
+		return edits[i].pos < edits[j].pos
+	})
+	lit, _ := expr.(*ast.CompositeLit)
+	return lit, sc, edits
+}
+
+// Applies the edits of a map after its literal, set is called for every
+// assignment. Returns false when one could not be evaluated.
+func (l *loader) applyEdits(edits []*mapEdit, set func(key string, e *mapEdit), remove func(key string)) bool {
+	ok := true
+	for _, e := range edits {
+		if e.key == nil {
+			ok = false
+			continue
+		}
+		key, isString := l.stringValue(e.key, e.sc)
+		switch {
+		case !isString:
+			ok = false
+		case e.value == nil:
+			remove(key)
+		default:
+			set(key, e)
+		}
+	}
+	return ok
+}
+
+// A keyed field of a struct literal
+type field struct {
+	name  string
+	value ast.Expr
+}
+
+// Keyed fields of a struct literal in source order, so dumps are
+// reproducible
+func fields(lit *ast.CompositeLit) []field {
+	var f []field
+	for _, elt := range lit.Elts {
+		if kv, ok := elt.(*ast.KeyValueExpr); ok {
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				f = append(f, field{key.Name, kv.Value})
+			}
+		}
+	}
+	return f
+}
+
+// Name of the type of a composite literal ex: Schema for schema.Schema
+func typeName(lit *ast.CompositeLit) string {
+	switch t := lit.Type.(type) {
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+func (l *loader) position(pos token.Pos) *schema.PositionDump {
+	position := l.fset.Position(pos)
+	filename, err := filepath.Rel(l.root, position.Filename)
+	if err != nil {
+		filename = position.Filename
+	}
+	return &schema.PositionDump{
+		Filename: filepath.ToSlash(filename),
+		Line:     position.Line,
+	}
+}
+
+func (l *loader) constant(expr ast.Expr, sc scope) constant.Value {
+	for depth := 0; depth < maxDepth; depth++ {
+		if tv, ok := sc.pkg.info.Types[expr]; ok && tv.Value != nil {
+			return tv.Value
+		}
+		switch e := expr.(type) {
+		case *ast.BasicLit:
+			return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+		case *ast.Ident:
+			if e.Name == "true" || e.Name == "false" {
+				return constant.MakeBool(e.Name == "true")
+			}
+		}
+		// variables aren't constants to go/types but may hold one
+		resolved, rsc := l.resolve(expr, sc, maxDepth-1)
+		if resolved == expr {
+			return nil
+		}
+		expr, sc = resolved, rsc
+	}
+	return nil
+}
+
+func (l *loader) stringValue(expr ast.Expr, sc scope) (string, bool) {
+	if v := l.constant(expr, sc); v != nil && v.Kind() == constant.String {
+		return constant.StringVal(v), true
+	}
+	return "", false
+}
+
+func (l *loader) boolValue(expr ast.Expr, sc scope) (bool, bool) {
+	if v := l.constant(expr, sc); v != nil && v.Kind() == constant.Bool {
+		return constant.BoolVal(v), true
+	}
+	return false, false
+}
+
+func (l *loader) intValue(expr ast.Expr, sc scope) (int64, bool) {
+	if v := l.constant(expr, sc); v != nil && v.Kind() == constant.Int {
+		return constant.Int64Val(v)
+	}
+	return 0, false
+}
+
+func (l *loader) defaultValue(expr ast.Expr, sc scope) (interface{}, bool) {
+	if ident, ok := expr.(*ast.Ident); ok && ident.Name == "nil" {
+		return nil, true
+	}
+	v := l.constant(expr, sc)
+	if v == nil {
+		return nil, false
+	}
+	switch v.Kind() {
+	case constant.Bool:
+		return constant.BoolVal(v), true
+	case constant.String:
+		return constant.StringVal(v), true
+	case constant.Int:
+		i, ok := constant.Int64Val(v)
+		return int(i), ok
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return f, true
+	}
+	return nil, false
+}
+
+func (l *loader) stringSlice(expr ast.Expr, sc scope) ([]string, bool) {
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		return nil, false
+	}
+	var values []string
+	for _, elt := range lit.Elts {
+		v, ok := l.stringValue(elt, lsc)
+		if !ok {
+			return values, false
+		}
+		values = append(values, v)
+	}
+	// dumps keep their string lists sorted
+	sort.Strings(values)
+	return values, true
+}
+
+func (l *loader) valueType(expr ast.Expr, sc scope) (tfschema.ValueType, bool) {
+	expr, _ = l.resolve(expr, sc, 0)
+	var name string
+	switch e := expr.(type) {
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	case *ast.Ident:
+		name = e.Name
+	}
+	t, ok := valueTypes[name]
+	return t, ok
+}
+
+const stringInSlice = "validation.StringInSlice"
+
+// The values of a validation.StringInSlice call, following variables and
+// functions returning the call. nil unless every value is known.
+func (l *loader) allowedValues(expr ast.Expr, sc scope) []string {
+	for depth := 0; depth < maxDepth; depth++ {
+		if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) > 0 {
+			if f := l.funcDump(call.Fun, sc); f != nil && f.Name == stringInSlice {
+				values, ok := l.stringSlice(call.Args[0], sc)
+				if !ok {
+					return nil
+				}
+				return values
+			}
+		}
+		resolved, rsc := l.resolve(expr, sc, maxDepth-1)
+		if resolved == expr {
+			return nil
+		}
+		expr, sc = resolved, rsc
+	}
+	return nil
+}
+
+// Names functions the way schema.NewFuncDump does at runtime, a call is
+// named after the function returning the closure
+func (l *loader) funcDump(expr ast.Expr, sc scope) *schema.FuncDump {
+	for depth := 0; depth < maxDepth; depth++ {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			if e.Name == "nil" {
+				return nil
+			}
+			if fn, ok := sc.pkg.info.Uses[e].(*types.Func); ok && fn.Pkg() != nil {
+				return &schema.FuncDump{Name: fn.Pkg().Name() + "." + fn.Name()}
+			}
+		case *ast.SelectorExpr:
+			if x, ok := e.X.(*ast.Ident); ok {
+				if pkgName, ok := sc.pkg.info.Uses[x].(*types.PkgName); ok {
+					return &schema.FuncDump{Name: pkgName.Imported().Name() + "." + e.Sel.Name}
+				}
+			}
+		case *ast.CallExpr:
+			expr = e.Fun
+			continue
+		case *ast.FuncLit:
+			name := sc.pkg.name + ".glob."
+			if sc.fn != nil {
+				name = sc.pkg.name + "." + sc.fn.Name.Name
+			}
+			return &schema.FuncDump{Name: name}
+		}
+		// a variable holding a function
+		resolved, rsc := l.resolve(expr, sc, maxDepth-1)
+		if resolved == expr {
+			return &schema.FuncDump{}
+		}
+		expr, sc = resolved, rsc
+	}
+	return &schema.FuncDump{}
+}
+
+// Fills m from a map[string]*schema.Schema, returns false if it is not
+// fully resolved
+func (l *loader) schemaMap(expr ast.Expr, sc scope, m map[string]*schema.SchemaDump) bool {
+	lit, lsc, edits := l.mapLit(expr, sc)
+	if lit == nil {
+		return false
+	}
+	ok := true
+	for _, elt := range lit.Elts {
+		kv, isKV := elt.(*ast.KeyValueExpr)
+		if !isKV {
+			ok = false
+			continue
+		}
+		key, isString := l.stringValue(kv.Key, lsc)
+		if !isString {
+			ok = false
+			continue
+		}
+		s := l.schema(kv.Value, lsc)
+		s.Position = l.position(kv.Key.Pos())
+		m[key] = s
+	}
+	set := func(key string, e *mapEdit) {
+		s := l.schema(e.value, e.sc)
+		s.Position = l.position(e.pos)
+		m[key] = s
+	}
+	remove := func(key string) {
+		delete(m, key)
+	}
+	return l.applyEdits(edits, set, remove) && ok
+}
+
+// Fills m from a map[string]*schema.Resource, returns false if it is not
+// fully resolved
+func (l *loader) resourceMap(expr ast.Expr, sc scope, m map[string]*schema.ResourceDump) bool {
+	lit, lsc, edits := l.mapLit(expr, sc)
+	if lit == nil {
+		return false
+	}
+	ok := true
+	for _, elt := range lit.Elts {
+		kv, isKV := elt.(*ast.KeyValueExpr)
+		if !isKV {
+			ok = false
+			continue
+		}
+		key, isString := l.stringValue(kv.Key, lsc)
+		if !isString {
+			ok = false
+			continue
+		}
+		m[key] = l.resource(kv.Value, lsc)
+	}
+	set := func(key string, e *mapEdit) {
+		m[key] = l.resource(e.value, e.sc)
+	}
+	remove := func(key string) {
+		delete(m, key)
+	}
+	return l.applyEdits(edits, set, remove) && ok
+}
+
+func (l *loader) schema(expr ast.Expr, sc scope) *schema.SchemaDump {
+	s := &schema.SchemaDump{}
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		s.Unresolved = []string{schema.UnresolvedAll}
+		return s
+	}
+
+	for _, f := range fields(lit) {
+		name, value := f.name, f.value
+		ok := true
+		switch name {
+		case "Type":
+			s.Type, ok = l.valueType(value, lsc)
+		case "Optional":
+			s.Optional, ok = l.boolValue(value, lsc)
+		case "Required":
+			s.Required, ok = l.boolValue(value, lsc)
+		case "Computed":
+			s.Computed, ok = l.boolValue(value, lsc)
+		case "ForceNew":
+			s.ForceNew, ok = l.boolValue(value, lsc)
+		case "PromoteSingle":
+			s.PromoteSingle, ok = l.boolValue(value, lsc)
+		case "Sensitive":
+			s.Sensitive, ok = l.boolValue(value, lsc)
+		case "Description":
+			s.Description, ok = l.stringValue(value, lsc)
+		case "InputDefault":
+			s.InputDefault, ok = l.stringValue(value, lsc)
+		case "Deprecated":
+			s.Deprecated, ok = l.stringValue(value, lsc)
+		case "Removed":
+			s.Removed, ok = l.stringValue(value, lsc)
+		case "Default":
+			s.Default, ok = l.defaultValue(value, lsc)
+		case "MaxItems":
+			var i int64
+			i, ok = l.intValue(value, lsc)
+			s.MaxItems = int(i)
+		case "MinItems":
+			var i int64
+			i, ok = l.intValue(value, lsc)
+			s.MinItems = int(i)
+		case "ComputedWhen":
+			s.ComputedWhen, ok = l.stringSlice(value, lsc)
+		case "ConflictsWith":
+			s.ConflictsWith, ok = l.stringSlice(value, lsc)
+		case "Elem":
+			s.Elem, ok = l.elem(value, lsc)
+		case "DefaultFunc":
+			s.DefaultFunc = l.funcDump(value, lsc)
+		case "DiffSuppressFunc":
+			s.DiffSuppressFunc = l.funcDump(value, lsc)
+		case "StateFunc":
+			s.StateFunc = l.funcDump(value, lsc)
+		case "Set":
+			s.Set = l.funcDump(value, lsc)
+		case "ValidateFunc":
+			s.ValidateFunc = l.funcDump(value, lsc)
+			if s.ValidateFunc != nil {
+				s.ValidateFunc.AllowedValues = l.allowedValues(value, lsc)
+			}
+		}
+		if !ok {
+			s.Unresolved = append(s.Unresolved, name)
+		}
+	}
+
+	return s
+}
+
+func (l *loader) elem(expr ast.Expr, sc scope) (interface{}, bool) {
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		return nil, false
+	}
+	switch typeName(lit) {
+	case "Schema":
+		return l.schema(lit, lsc), true
+	case "Resource":
+		return l.resource(lit, lsc), true
+	}
+	return nil, false
+}
+
+func (l *loader) resource(expr ast.Expr, sc scope) *schema.ResourceDump {
+	r := &schema.ResourceDump{}
+	r.Schema = make(map[string]*schema.SchemaDump)
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		r.Unresolved = []string{schema.UnresolvedAll}
+		return r
+	}
+	r.Position = l.position(lit.Pos())
+
+	for _, f := range fields(lit) {
+		name, value := f.name, f.value
+		ok := true
+		switch name {
+		case "Schema":
+			ok = l.schemaMap(value, lsc, r.Schema)
+		case "SchemaVersion":
+			var i int64
+			i, ok = l.intValue(value, lsc)
+			r.SchemaVersion = int(i)
+		case "DeprecationMessage":
+			r.DeprecationMessage, ok = l.stringValue(value, lsc)
+		case "Timeouts":
+			r.Timeouts, ok = l.timeouts(value, lsc)
+		case "Update":
+			r.Update = l.funcDump(value, lsc)
+		case "Exists":
+			r.Exists = l.funcDump(value, lsc)
+		case "CustomizeDiff":
+			r.CustomizeDiff = l.funcDump(value, lsc)
+		case "MigrateState":
+			r.MigrateState = l.funcDump(value, lsc)
+		case "Importer":
+			r.Importer, ok = l.importer(value, lsc)
+		}
+		if !ok {
+			r.Unresolved = append(r.Unresolved, name)
+		}
+	}
+
+	return r
+}
+
+// schema.DefaultTimeout(10 * time.Minute)
+func (l *loader) timeouts(expr ast.Expr, sc scope) (*tfschema.ResourceTimeout, bool) {
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		return nil, false
+	}
+	t := &tfschema.ResourceTimeout{}
+	for _, f := range fields(lit) {
+		name, value := f.name, f.value
+		call, ok := value.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 {
+			return t, false
+		}
+		ns, ok := l.intValue(call.Args[0], lsc)
+		if !ok {
+			return t, false
+		}
+		d := time.Duration(ns)
+		switch name {
+		case "Create":
+			t.Create = &d
+		case "Read":
+			t.Read = &d
+		case "Update":
+			t.Update = &d
+		case "Delete":
+			t.Delete = &d
+		case "Default":
+			t.Default = &d
+		}
+	}
+	return t, true
+}
+
+func (l *loader) importer(expr ast.Expr, sc scope) (*schema.ImporterDump, bool) {
+	if ident, ok := expr.(*ast.Ident); ok && ident.Name == "nil" {
+		return nil, true
+	}
+	lit, lsc := l.compositeLit(expr, sc, 0)
+	if lit == nil {
+		return nil, false
+	}
+	im := &schema.ImporterDump{}
+	for _, f := range fields(lit) {
+		if f.name == "State" {
+			im.State = l.funcDump(f.value, lsc)
+		}
+	}
+	im.Passthrough = im.State == nil || im.State.Name == "schema.ImportStatePassthrough"
+	return im, true
+}
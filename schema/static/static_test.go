@@ -0,0 +1,66 @@
+package static
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestExtractMapEdits(t *testing.T) {
+	dump, err := Extract("testdata/terraform-provider-x", "example.com/terraform-provider-x", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dump.Unresolved != nil {
+		t.Errorf("provider Unresolved = %q, want none", dump.Unresolved)
+	}
+
+	cases := []struct {
+		resource   string
+		keys       []string
+		unresolved []string
+	}{
+		{"x_literal", []string{"name"}, nil},
+		{"x_assigned", []string{"kind", "name"}, nil},
+		{"x_deleted", []string{"kind"}, nil},
+		// keys that aren't constants can't be known
+		{"x_looped", []string{"name"}, []string{"Schema"}},
+		// the function may change the map
+		{"x_passed", []string{"name"}, []string{"Schema"}},
+		{"x_nested", []string{"name"}, []string{"Schema"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.resource, func(t *testing.T) {
+			r, ok := dump.ResourcesMap[c.resource]
+			if !ok {
+				t.Fatalf("%s missing from ResourcesMap", c.resource)
+			}
+			var keys []string
+			for key := range r.Schema {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			if !reflect.DeepEqual(keys, c.keys) {
+				t.Errorf("Schema keys = %q, want %q", keys, c.keys)
+			}
+			if !reflect.DeepEqual(r.Unresolved, c.unresolved) {
+				t.Errorf("Unresolved = %q, want %q", r.Unresolved, c.unresolved)
+			}
+		})
+	}
+}
+
+func TestExtractMapEditPosition(t *testing.T) {
+	dump, err := Extract("testdata/terraform-provider-x", "example.com/terraform-provider-x", "x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kind := dump.ResourcesMap["x_assigned"].Schema["kind"]
+	if kind.Position == nil || kind.Position.Filename != "x/provider.go" || kind.Position.Line != 37 {
+		t.Errorf("Position = %+v, want x/provider.go:37", kind.Position)
+	}
+	if !kind.Optional || kind.Unresolved != nil {
+		t.Errorf("kind = %+v, want a resolved optional attribute", kind)
+	}
+}
@@ -0,0 +1,73 @@
+package x
+
+import (
+	"github.com/hashicorp/terraform/helper/schema"
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func Provider() terraform.ResourceProvider {
+	resources := map[string]*schema.Resource{
+		"x_literal": resourceLiteral(),
+	}
+	resources["x_assigned"] = resourceAssigned()
+	resources["x_deleted"] = resourceDeleted()
+	resources["x_looped"] = resourceLooped()
+	resources["x_passed"] = resourcePassed()
+	resources["x_nested"] = resourceNested()
+
+	return &schema.Provider{
+		ResourcesMap: resources,
+	}
+}
+
+func baseSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"name": {Type: schema.TypeString, Required: true},
+	}
+}
+
+func resourceLiteral() *schema.Resource {
+	return &schema.Resource{
+		Schema: baseSchema(),
+	}
+}
+
+func resourceAssigned() *schema.Resource {
+	s := baseSchema()
+	s["kind"] = &schema.Schema{
+		Type:     schema.TypeString,
+		Optional: true,
+	}
+	return &schema.Resource{Schema: s}
+}
+
+func resourceDeleted() *schema.Resource {
+	s := baseSchema()
+	s["kind"] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	delete(s, "name")
+	return &schema.Resource{Schema: s}
+}
+
+func resourceLooped() *schema.Resource {
+	s := baseSchema()
+	for _, key := range []string{"a", "b"} {
+		s[key] = &schema.Schema{Type: schema.TypeString, Optional: true}
+	}
+	return &schema.Resource{Schema: s}
+}
+
+func resourcePassed() *schema.Resource {
+	s := baseSchema()
+	addTags(s)
+	return &schema.Resource{Schema: s}
+}
+
+func resourceNested() *schema.Resource {
+	s := baseSchema()
+	s["name"].Computed = true
+	return &schema.Resource{Schema: s}
+}
+
+func addTags(s map[string]*schema.Schema) {
+	s["tags"] = &schema.Schema{Type: schema.TypeMap, Optional: true}
+}
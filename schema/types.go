@@ -3,6 +3,11 @@ package schema
 import (
 	"encoding/json"
 	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
@@ -14,6 +19,9 @@ type ProviderDump struct {
 	Schema         map[string]*SchemaDump
 	ResourcesMap   map[string]*ResourceDump
 	DataSourcesMap map[string]*ResourceDump
+
+	// fields static extraction could not evaluate
+	Unresolved []string `json:",omitempty"`
 }
 
 func (p *ProviderDump) ToJSON(w io.Writer) error {
@@ -41,6 +49,38 @@ func NewProviderDump(providerFunc func() terraform.ResourceProvider) *ProviderDu
 	return p
 }
 
+// Copypaste of schema.Provisioner but removes functions or anything else
+// that will fail to serialize
+type ProvisionerDump struct {
+	Schema map[string]*SchemaDump
+	// the connection block of the provisioner
+	ConnSchema map[string]*SchemaDump
+
+	// fields static extraction could not evaluate
+	Unresolved []string `json:",omitempty"`
+}
+
+func (p *ProvisionerDump) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "    ")
+	return enc.Encode(p)
+}
+
+// Converts terraform/helper/schema.Provisioner to ProvisionerDump
+func NewProvisionerDump(provisionerFunc func() terraform.ResourceProvisioner) *ProvisionerDump {
+	i := provisionerFunc().(*schema.Provisioner)
+	p := &ProvisionerDump{}
+	p.Schema = make(map[string]*SchemaDump)
+	for key, value := range i.Schema {
+		p.Schema[key] = NewSchemaDump(value)
+	}
+	p.ConnSchema = make(map[string]*SchemaDump)
+	for key, value := range i.ConnSchema {
+		p.ConnSchema[key] = NewSchemaDump(value)
+	}
+	return p
+}
+
 // Copypaste of schema.Resource but removes functions or anything else
 // that will fail to serialize
 type ResourceDump struct {
@@ -48,6 +88,17 @@ type ResourceDump struct {
 	SchemaVersion      int
 	DeprecationMessage string
 	Timeouts           *schema.ResourceTimeout
+
+	// lifecycle functions, nil when not implemented by the resource
+	Update        *FuncDump
+	Exists        *FuncDump
+	CustomizeDiff *FuncDump
+	MigrateState  *FuncDump
+	Importer      *ImporterDump
+
+	// static extraction only, see SchemaDump
+	Unresolved []string      `json:",omitempty"`
+	Position   *PositionDump `json:",omitempty"`
 }
 
 // Converts terraform/helper/schema.Resource to ResourceDump
@@ -56,6 +107,13 @@ func NewResourceDump(i *schema.Resource) *ResourceDump {
 	r.SchemaVersion = i.SchemaVersion
 	r.DeprecationMessage = i.DeprecationMessage
 	r.Timeouts = i.Timeouts
+	r.Update = NewFuncDump(i.Update)
+	r.Exists = NewFuncDump(i.Exists)
+	r.CustomizeDiff = NewFuncDump(i.CustomizeDiff)
+	r.MigrateState = NewFuncDump(i.MigrateState)
+	if i.Importer != nil {
+		r.Importer = NewImporterDump(i.Importer)
+	}
 	r.Schema = make(map[string]*SchemaDump)
 	for key, value := range i.Schema {
 		r.Schema[key] = NewSchemaDump(value)
@@ -63,6 +121,24 @@ func NewResourceDump(i *schema.Resource) *ResourceDump {
 	return r
 }
 
+// Copypaste of schema.ResourceImporter but removes functions or anything else
+// that will fail to serialize
+type ImporterDump struct {
+	State *FuncDump
+	// The ID is passed straight through, either State is
+	// schema.ImportStatePassthrough or State is not set
+	Passthrough bool
+}
+
+// Converts terraform/helper/schema.ResourceImporter to ImporterDump
+func NewImporterDump(i *schema.ResourceImporter) *ImporterDump {
+	im := &ImporterDump{}
+	im.State = NewFuncDump(i.State)
+	im.Passthrough = i.State == nil ||
+		reflect.ValueOf(i.State).Pointer() == reflect.ValueOf(schema.ImportStatePassthrough).Pointer()
+	return im
+}
+
 // Copypaste of schema.Schema but removes functions or anything else
 // that will fail to serialize
 type SchemaDump struct {
@@ -83,6 +159,20 @@ type SchemaDump struct {
 	Deprecated    string
 	Removed       string
 	Sensitive     bool
+
+	// behavioral functions can't be serialized, only their presence
+	// and name are recorded, nil when the function is not set
+	DefaultFunc      *FuncDump
+	DiffSuppressFunc *FuncDump
+	StateFunc        *FuncDump
+	Set              *FuncDump
+	ValidateFunc     *FuncDump
+
+	// fields static extraction could not evaluate, UnresolvedAll when
+	// the schema itself could not be found
+	Unresolved []string `json:",omitempty"`
+	// where the attribute is declared, only known to static extraction
+	Position *PositionDump `json:",omitempty"`
 }
 
 // Converts terraform/helper/schema.Schema to SchemaDump
@@ -99,11 +189,16 @@ func NewSchemaDump(i *schema.Schema) *SchemaDump {
 	s.MaxItems = i.MaxItems
 	s.MinItems = i.MinItems
 	s.PromoteSingle = i.PromoteSingle
-	s.ComputedWhen = i.ComputedWhen
-	s.ConflictsWith = i.ConflictsWith
+	s.ComputedWhen = sortedStrings(i.ComputedWhen)
+	s.ConflictsWith = sortedStrings(i.ConflictsWith)
 	s.Deprecated = i.Deprecated
 	s.Removed = i.Removed
 	s.Sensitive = i.Sensitive
+	s.DefaultFunc = NewFuncDump(i.DefaultFunc)
+	s.DiffSuppressFunc = NewFuncDump(i.DiffSuppressFunc)
+	s.StateFunc = NewFuncDump(i.StateFunc)
+	s.Set = NewFuncDump(i.Set)
+	s.ValidateFunc = NewFuncDump(i.ValidateFunc)
 	if i.Elem != nil {
 		if nestedSchema, ok := i.Elem.(*schema.Schema); ok {
 			s.Elem = NewSchemaDump(nestedSchema)
@@ -113,3 +208,97 @@ func NewSchemaDump(i *schema.Schema) *SchemaDump {
 	}
 	return s
 }
+
+// Decodes Elem back into a *SchemaDump or *ResourceDump, a nested resource
+// is told apart by its Schema field
+func (s *SchemaDump) UnmarshalJSON(data []byte) error {
+	type plain SchemaDump
+	aux := &struct {
+		*plain
+		Elem json.RawMessage
+	}{plain: (*plain)(s)}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	s.Elem = nil
+	if len(aux.Elem) == 0 || string(aux.Elem) == "null" {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(aux.Elem, &fields); err != nil {
+		return err
+	}
+	if _, ok := fields["Schema"]; ok {
+		r := &ResourceDump{}
+		if err := json.Unmarshal(aux.Elem, r); err != nil {
+			return err
+		}
+		s.Elem = r
+		return nil
+	}
+	nested := &SchemaDump{}
+	if err := json.Unmarshal(aux.Elem, nested); err != nil {
+		return err
+	}
+	s.Elem = nested
+	return nil
+}
+
+// Lists are sorted so dumps are reproducible, the schema's own slice is
+// left untouched
+func sortedStrings(values []string) []string {
+	if values == nil {
+		return nil
+	}
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// Marks a dump static extraction could not evaluate at all
+const UnresolvedAll = "*"
+
+// Location in the provider source, Filename is relative to the provider
+type PositionDump struct {
+	Filename string
+	Line     int
+}
+
+// Records a function assigned to a schema field, the function itself
+// can't be serialized
+type FuncDump struct {
+	// Resolved name of the function ex: validation.StringInSlice
+	Name string
+	// values accepted by validation.StringInSlice, only static extraction
+	// can see the arguments of the call
+	AllowedValues []string
+}
+
+// closures are named after their enclosing function with a .funcN suffix,
+// method values with a -fm suffix
+var funcSuffix = regexp.MustCompile(`(\.func\d+)+$|-fm$`)
+
+// Converts a function to FuncDump, returns nil if the function is not set
+func NewFuncDump(f interface{}) *FuncDump {
+	v := reflect.ValueOf(f)
+	if v.Kind() != reflect.Func || v.IsNil() {
+		return nil
+	}
+
+	fn := runtime.FuncForPC(v.Pointer())
+	if fn == nil {
+		return &FuncDump{}
+	}
+
+	// github.com/hashicorp/terraform/helper/validation.StringInSlice.func1
+	// becomes validation.StringInSlice
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i > -1 {
+		name = name[i+1:]
+	}
+	name = funcSuffix.ReplaceAllString(name, "")
+
+	return &FuncDump{Name: name}
+}
@@ -0,0 +1,72 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestSchemaDumpUnmarshalJSON(t *testing.T) {
+	cases := []struct {
+		name string
+		dump *SchemaDump
+	}{
+		{"no elem", &SchemaDump{Type: schema.TypeString, Optional: true}},
+		{"schema elem", &SchemaDump{Type: schema.TypeList, Elem: &SchemaDump{Type: schema.TypeInt}}},
+		{"nested schema elem", &SchemaDump{Type: schema.TypeList, Elem: &SchemaDump{Type: schema.TypeList, Elem: &SchemaDump{Type: schema.TypeString}}}},
+		{"resource elem", &SchemaDump{Type: schema.TypeSet, Elem: &ResourceDump{Schema: map[string]*SchemaDump{
+			"port": {Type: schema.TypeInt, Required: true},
+			"cidr": {Type: schema.TypeList, Optional: true, Elem: &SchemaDump{Type: schema.TypeString}},
+		}}}},
+		// a block without attributes is still a block
+		{"empty resource elem", &SchemaDump{Type: schema.TypeList, Elem: &ResourceDump{}}},
+		{"static extraction", &SchemaDump{
+			Type:       schema.TypeString,
+			Unresolved: []string{"Default", "ValidateFunc"},
+			Position:   &PositionDump{Filename: "x/provider.go", Line: 7},
+		}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := json.Marshal(c.dump)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// decoding into a used dump must not keep its old Elem
+			s := &SchemaDump{Elem: &SchemaDump{}}
+			if err := json.Unmarshal(data, s); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(s, c.dump) {
+				t.Errorf("round trip of %s = %+v, want %+v", data, s, c.dump)
+			}
+		})
+	}
+}
+
+func TestSchemaDumpUnmarshalJSONErrors(t *testing.T) {
+	for _, data := range []string{`{"Elem": 1}`, `{"Elem": {"Type": "x"}}`, `{"Elem": {"Schema": []}}`} {
+		if err := json.Unmarshal([]byte(data), &SchemaDump{}); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", data)
+		}
+	}
+}
+
+// dumps not from static extraction have no Unresolved or Position
+func TestDumpOmitsStaticFields(t *testing.T) {
+	data, err := json.Marshal(&ProviderDump{
+		Schema:       map[string]*SchemaDump{"region": {Type: schema.TypeString}},
+		ResourcesMap: map[string]*ResourceDump{"x_thing": {}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"Unresolved"`, `"Position"`} {
+		if strings.Contains(string(data), field) {
+			t.Errorf("dump has %s: %s", field, data)
+		}
+	}
+}
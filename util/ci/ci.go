@@ -0,0 +1,182 @@
+package ci
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/appilon/tfplugin/util"
+	goversion "github.com/hashicorp/go-version"
+)
+
+// A CI system whose config sets the version of go builds run with
+type Config interface {
+	// Name used in messages, ex: TravisCI
+	Name() string
+	// Config files of the provider, none when it doesn't use this CI
+	Files(providerPath string) ([]string, error)
+	// Versions of go set in content, as major.minor or major.minor.patch
+	GoVersions(content []byte) ([]string, error)
+	// Raises every version of go in content older than the major.minor
+	// version to it, content is returned as is when there's nothing to set
+	SetGoVersion(content []byte, version string) ([]byte, error)
+}
+
+// Every supported CI, in the order versions are detected in
+var Configs = []Config{travis{}, githubActions{}, circleCI{}, teamCity{}}
+
+// A config file found in a provider
+type File struct {
+	Config Config
+	Path   string
+}
+
+func Find(providerPath string) ([]File, error) {
+	var files []File
+	for _, c := range Configs {
+		paths, err := c.Files(providerPath)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			files = append(files, File{Config: c, Path: path})
+		}
+	}
+	return files, nil
+}
+
+// The first version of go set by the provider's CI configs
+func DetectGoVersion(providerPath string) (string, error) {
+	files, err := Find(providerPath)
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no CI config in %s", providerPath)
+	}
+	for _, f := range files {
+		content, err := ioutil.ReadFile(f.Path)
+		if err != nil {
+			return "", err
+		}
+		versions, err := f.Config.GoVersions(content)
+		if err != nil {
+			return "", fmt.Errorf("%s: %s", f.Path, err)
+		}
+		if len(versions) > 0 {
+			return versions[0], nil
+		}
+	}
+	return "", fmt.Errorf("no version of go in CI config of %s", providerPath)
+}
+
+// Raises the version of go in every CI config of the provider, the files
+// changed are returned
+func SetGoVersion(providerPath string, version string, stage *util.Stage) ([]File, error) {
+	files, err := Find(providerPath)
+	if err != nil {
+		return nil, err
+	}
+	var changed []File
+	for _, f := range files {
+		content, err := stage.ReadFile(f.Path)
+		if err != nil {
+			return nil, err
+		}
+		out, err := f.Config.SetGoVersion(content, version)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f.Path, err)
+		}
+		if string(out) == string(content) {
+			continue
+		}
+		if err := stage.WriteFile(f.Path, out); err != nil {
+			return nil, err
+		}
+		changed = append(changed, f)
+	}
+	return changed, nil
+}
+
+// major.minor with an optional patch, suffixes like .x or -browsers and
+// prefixes like ^ are left around it
+var versionRegexp = regexp.MustCompile(`\d+\.\d+(\.\d+)?`)
+
+func goVersion(s string) string {
+	return versionRegexp.FindString(s)
+}
+
+// Whether the version of go in value is older than version, values without
+// one like tip or ${{ matrix.go }} are not
+func older(value string, version string) bool {
+	v, err := goversion.NewVersion(goVersion(value))
+	if err != nil {
+		return false
+	}
+	to, err := goversion.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return v.LessThan(to)
+}
+
+// Replaces the version in a YAML value, keeping its quotes, prefix and
+// suffix. Unquoted numbers are quoted so 1.10 doesn't become 1.1.
+func replaceVersion(value string, version string) string {
+	out := versionRegexp.ReplaceAllLiteralString(value, version)
+	if _, err := strconv.ParseFloat(out, 64); err == nil {
+		out = strconv.Quote(out)
+	}
+	return out
+}
+
+// Writes version in place of the one in a value, see replaceVersion
+func keepStyle(version string) func(string) string {
+	return func(value string) string {
+		return replaceVersion(value, version)
+	}
+}
+
+// Files in dir with one of the extensions, none when dir doesn't exist
+func filesIn(dir string, recursive bool, extensions ...string) ([]string, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && !recursive {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		for _, ext := range extensions {
+			if strings.HasSuffix(path, ext) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return files, err
+}
+
+// The first of filenames existing in dir
+func oneOf(dir string, filenames ...string) ([]string, error) {
+	for _, filename := range filenames {
+		path := filepath.Join(dir, filename)
+		if _, err := os.Stat(path); err == nil {
+			return []string{path}, nil
+		} else if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
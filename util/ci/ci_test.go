@@ -0,0 +1,209 @@
+package ci
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/appilon/tfplugin/util"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of testdata")
+
+func TestConfigs(t *testing.T) {
+	cases := []struct {
+		config   Config
+		fixture  string
+		versions []string
+		// testdata file SetGoVersion to 1.14 should write, empty when the
+		// config is left as is
+		golden string
+	}{
+		{travis{}, "travis.yml", []string{"1.12"}, "travis.yml.golden"},
+		{travis{}, "travis-matrix.yml", []string{"1.11", "1.12", "1.13", "1.12", "1.14"}, "travis-matrix.yml.golden"},
+		{travis{}, "travis-python.yml", nil, ""},
+		{githubActions{}, "github.yml", []string{"1.13"}, "github.yml.golden"},
+		{githubActions{}, "github-matrix.yml", []string{"1.12", "1.13", "1.15"}, "github-matrix.yml.golden"},
+		{circleCI{}, "circleci.yml", []string{"1.16", "1.12.7"}, "circleci.yml.golden"},
+		{teamCity{}, "settings.kts", []string{"1.13.8", "1.13", "1.16"}, "settings.kts.golden"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.fixture, func(t *testing.T) {
+			content, err := ioutil.ReadFile(filepath.Join("testdata", c.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			versions, err := c.config.GoVersions(content)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(versions, c.versions) {
+				t.Errorf("GoVersions = %q, want %q", versions, c.versions)
+			}
+
+			out, err := c.config.SetGoVersion(content, "1.14")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.golden == "" {
+				if string(out) != string(content) {
+					t.Errorf("SetGoVersion changed the config:\n%s", out)
+				}
+				return
+			}
+			golden := filepath.Join("testdata", c.golden)
+			if *update {
+				if err := ioutil.WriteFile(golden, out, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != string(want) {
+				t.Errorf("SetGoVersion diff:\n%s", util.UnifiedDiff(c.golden, want, out))
+			}
+
+			// running the upgrade again changes nothing
+			again, err := c.config.SetGoVersion(out, "1.14")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(out) {
+				t.Errorf("second SetGoVersion diff:\n%s", util.UnifiedDiff(c.golden, out, again))
+			}
+		})
+	}
+}
+
+func TestOlder(t *testing.T) {
+	cases := []struct {
+		value, version string
+		older          bool
+	}{
+		{"1.12", "1.14", true},
+		{`"1.12.x"`, "1.14", true},
+		{"circleci/golang:1.13.8-browsers", "1.14", true},
+		{"1.14", "1.14", false},
+		{"1.14.2", "1.14", false},
+		{"1.16", "1.14", false},
+		{"1.9", "1.14", true},
+		{"1.10", "1.9", false},
+		{"tip", "1.14", false},
+		{"${{ matrix.go }}", "1.14", false},
+	}
+	for _, c := range cases {
+		if older := older(c.value, c.version); older != c.older {
+			t.Errorf("older(%q, %q) = %t, want %t", c.value, c.version, older, c.older)
+		}
+	}
+}
+
+func TestReplaceVersion(t *testing.T) {
+	cases := []struct {
+		value, version, want string
+	}{
+		{"1.12", "1.14", `"1.14"`},
+		{"1.9", "1.10", `"1.10"`},
+		{"'1.12'", "1.14", "'1.14'"},
+		{"1.12.x", "1.14", "1.14.x"},
+		{`"1.12.7"`, "1.14", `"1.14"`},
+		{"circleci/golang:1.12-browsers", "1.14", "circleci/golang:1.14-browsers"},
+		{"^1.12", "1.14", "^1.14"},
+	}
+	for _, c := range cases {
+		if out := replaceVersion(c.value, c.version); out != c.want {
+			t.Errorf("replaceVersion(%q, %q) = %q, want %q", c.value, c.version, out, c.want)
+		}
+	}
+}
+
+// Every config of a provider is found, versions are detected in the order
+// of Configs and a dry run leaves the files alone
+func TestProvider(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tfplugin-ci")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"github-matrix.yml": ".github/workflows/test.yml",
+		"github.yml":        ".github/workflows/release.yaml",
+		"circleci.yml":      ".circleci/config.yml",
+		"settings.kts":      ".teamcity/settings.kts",
+		"travis-python.yml": ".travis.yml",
+	}
+	for fixture, name := range files {
+		content, err := ioutil.ReadFile(filepath.Join("testdata", fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, f := range found {
+		rel, _ := filepath.Rel(dir, f.Path)
+		names = append(names, f.Config.Name()+" "+filepath.ToSlash(rel))
+	}
+	want := []string{
+		"TravisCI .travis.yml",
+		"GitHub Actions .github/workflows/release.yaml",
+		"GitHub Actions .github/workflows/test.yml",
+		"CircleCI .circleci/config.yml",
+		"TeamCity .teamcity/settings.kts",
+	}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("Find = %q, want %q", names, want)
+	}
+
+	// the python travis config has no version, the release workflow is next
+	v, err := DetectGoVersion(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "1.13" {
+		t.Errorf("DetectGoVersion = %q, want 1.13", v)
+	}
+
+	stage := util.NewStage(true)
+	changed, err := SetGoVersion(dir, "1.14", stage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changed) != 4 {
+		t.Errorf("SetGoVersion changed %d files, want 4", len(changed))
+	}
+	content, err := ioutil.ReadFile(filepath.Join(dir, ".github", "workflows", "release.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != readFixture(t, "github.yml") {
+		t.Error("a dry run wrote the provider's files")
+	}
+}
+
+func readFixture(t *testing.T, name string) string {
+	content, err := ioutil.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
@@ -0,0 +1,60 @@
+package ci
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/appilon/tfplugin/util/yamledit"
+)
+
+// Tags of the go docker images of .circleci/config.yml jobs and executors
+type circleCI struct{}
+
+// circleci/golang:1.12, cimg/go:1.13, golang:1.12-stretch
+var goImageRegexp = regexp.MustCompile(`^["']?(circleci/golang|cimg/go|golang):`)
+
+func (circleCI) Name() string {
+	return "CircleCI"
+}
+
+func (circleCI) Files(providerPath string) ([]string, error) {
+	return oneOf(filepath.Join(providerPath, ".circleci"), "config.yml", "config.yaml")
+}
+
+func (circleCI) GoVersions(content []byte) ([]string, error) {
+	doc := yamledit.Parse(content)
+	var versions []string
+	for _, path := range goImagePaths(doc) {
+		values, _ := doc.Values(path...)
+		if v := goVersion(values[0]); v != "" {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (circleCI) SetGoVersion(content []byte, version string) ([]byte, error) {
+	doc := yamledit.Parse(content)
+	for _, path := range goImagePaths(doc) {
+		setYAMLVersion(doc, version, keepStyle(version), path...)
+	}
+	return doc.Bytes(), nil
+}
+
+func goImagePaths(doc *yamledit.Document) [][]string {
+	var paths [][]string
+	for _, section := range []string{"jobs", "executors"} {
+		for _, name := range doc.Keys(section) {
+			docker := []string{section, name, "docker"}
+			for i := 0; i < doc.Len(docker...); i++ {
+				path := append(docker, strconv.Itoa(i), "image")
+				values, _ := doc.RawValues(path...)
+				if len(values) == 1 && goImageRegexp.MatchString(values[0]) {
+					paths = append(paths, path)
+				}
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,111 @@
+package ci
+
+import (
+	"log"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/appilon/tfplugin/util/yamledit"
+)
+
+// go-version of the actions/setup-go steps in .github/workflows, or the
+// strategy.matrix entry it refers to
+type githubActions struct{}
+
+// ${{ matrix.go }}
+var matrixRegexp = regexp.MustCompile(`\$\{\{\s*matrix\.([\w-]+)\s*\}\}`)
+
+func (githubActions) Name() string {
+	return "GitHub Actions"
+}
+
+func (githubActions) Files(providerPath string) ([]string, error) {
+	return filesIn(filepath.Join(providerPath, ".github", "workflows"), false, ".yml", ".yaml")
+}
+
+func (githubActions) GoVersions(content []byte) ([]string, error) {
+	doc := yamledit.Parse(content)
+	var versions []string
+	for _, path := range setupGoPaths(doc) {
+		values, _ := doc.Values(path...)
+		for _, v := range values {
+			if v := goVersion(v); v != "" {
+				versions = append(versions, v)
+			}
+		}
+	}
+	return versions, nil
+}
+
+func (githubActions) SetGoVersion(content []byte, version string) ([]byte, error) {
+	doc := yamledit.Parse(content)
+	for _, path := range setupGoPaths(doc) {
+		setYAMLVersion(doc, version, keepStyle(version), path...)
+	}
+	return doc.Bytes(), nil
+}
+
+func setupGoPaths(doc *yamledit.Document) [][]string {
+	var paths [][]string
+	seen := make(map[string]bool)
+	for _, job := range doc.Keys("jobs") {
+		steps := []string{"jobs", job, "steps"}
+		for i := 0; i < doc.Len(steps...); i++ {
+			step := append(steps, strconv.Itoa(i))
+			uses, _ := doc.Values(append(step, "uses")...)
+			if len(uses) != 1 || !strings.HasPrefix(uses[0], "actions/setup-go") {
+				continue
+			}
+			path := append(step, "with", "go-version")
+			values, ok := doc.Values(path...)
+			if !ok || len(values) != 1 {
+				continue
+			}
+			if match := matrixRegexp.FindStringSubmatch(values[0]); match != nil {
+				path = []string{"jobs", job, "strategy", "matrix", match[1]}
+			}
+			if key := strings.Join(path, "."); !seen[key] {
+				seen[key] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// Raises the versions at path older than version, each written by write.
+// A matrix keeps its width: the newest entries are raised first and an
+// entry that would repeat one already there is left as is and logged, so
+// 1.12, 1.13 and 1.20 raised to 1.14 become 1.12, 1.14 and 1.20.
+func setYAMLVersion(doc *yamledit.Document, version string, write func(value string) string, path ...string) {
+	values, _ := doc.RawValues(path...)
+	seen := make(map[string]bool)
+	var raise []int
+	for i, v := range values {
+		seen[goVersion(v)] = true
+		if older(v, version) {
+			raise = append(raise, i)
+		}
+	}
+	sort.SliceStable(raise, func(i, j int) bool {
+		return older(values[raise[j]], goVersion(values[raise[i]]))
+	})
+
+	out := append([]string(nil), values...)
+	changed := false
+	for _, i := range raise {
+		raised := write(values[i])
+		if seen[goVersion(raised)] {
+			log.Printf("Leaving go %s in %s, raising it to %s would repeat a matrix entry", values[i], strings.Join(path, "."), version)
+			continue
+		}
+		seen[goVersion(raised)] = true
+		out[i], changed = raised, true
+	}
+	if changed {
+		doc.SetValues(out, path...)
+	}
+}
@@ -0,0 +1,47 @@
+package ci
+
+import (
+	"path/filepath"
+	"regexp"
+)
+
+// Kotlin DSL of .teamcity, go versions are found by the assignments and
+// docker images they usually appear in
+type teamCity struct{}
+
+// val goVersion = "1.14", dockerImage = "golang:1.14"
+var teamCityRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(go_?version\s*=\s*")(\d+\.\d+(?:\.\d+)?)`),
+	regexp.MustCompile(`((?:circleci/golang|cimg/go|golang):)(\d+\.\d+(?:\.\d+)?)`),
+}
+
+func (teamCity) Name() string {
+	return "TeamCity"
+}
+
+func (teamCity) Files(providerPath string) ([]string, error) {
+	return filesIn(filepath.Join(providerPath, ".teamcity"), true, ".kts", ".kt")
+}
+
+func (teamCity) GoVersions(content []byte) ([]string, error) {
+	var versions []string
+	for _, r := range teamCityRegexps {
+		for _, match := range r.FindAllSubmatch(content, -1) {
+			versions = append(versions, string(match[2]))
+		}
+	}
+	return versions, nil
+}
+
+func (teamCity) SetGoVersion(content []byte, version string) ([]byte, error) {
+	for _, r := range teamCityRegexps {
+		content = r.ReplaceAllFunc(content, func(match []byte) []byte {
+			groups := r.FindSubmatch(match)
+			if !older(string(groups[2]), version) {
+				return match
+			}
+			return append(append([]byte{}, groups[1]...), version...)
+		})
+	}
+	return content, nil
+}
@@ -0,0 +1,24 @@
+import jetbrains.buildServer.configs.kotlin.v2019_2.*
+import jetbrains.buildServer.configs.kotlin.v2019_2.buildSteps.script
+
+version = "2020.1"
+
+val goVersion = "1.13.8"
+
+project {
+    buildType(Test)
+}
+
+object Test : BuildType({
+    name = "Test"
+    steps {
+        script {
+            scriptContent = "make test"
+            dockerImage = "golang:1.13"
+        }
+        script {
+            scriptContent = "make website-test"
+            dockerImage = "golang:1.16-alpine"
+        }
+    }
+})
@@ -0,0 +1,68 @@
+package ci
+
+import (
+	"fmt"
+
+	"github.com/appilon/tfplugin/util"
+	"github.com/appilon/tfplugin/util/yamledit"
+)
+
+// go of .travis.yml at the top level and in matrix or jobs includes
+type travis struct{}
+
+func (travis) Name() string {
+	return "TravisCI"
+}
+
+func (travis) Files(providerPath string) ([]string, error) {
+	return oneOf(providerPath, ".travis.yml", ".travis.yaml")
+}
+
+// Configs of other languages set no version of go
+func (travis) GoVersions(content []byte) ([]string, error) {
+	doc := yamledit.Parse(content)
+	if !util.IsGoTravis(doc) {
+		return nil, nil
+	}
+
+	var versions []string
+	for _, path := range travisGoPaths(doc) {
+		values, _ := doc.Values(path...)
+		for _, v := range values {
+			if v := goVersion(v); v != "" {
+				versions = append(versions, v)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// The latest patch release is picked with .x
+func (travis) SetGoVersion(content []byte, version string) ([]byte, error) {
+	doc := yamledit.Parse(content)
+	if !util.IsGoTravis(doc) {
+		return content, nil
+	}
+
+	latest := fmt.Sprintf(`"%s.x"`, version)
+	paths := travisGoPaths(doc)
+	if len(paths) == 0 {
+		doc.SetValues([]string{latest}, "go")
+	}
+	for _, path := range paths {
+		setYAMLVersion(doc, version, func(string) string { return latest }, path...)
+	}
+	return doc.Bytes(), nil
+}
+
+// go of the top level and of the jobs setting their own
+func travisGoPaths(doc *yamledit.Document) [][]string {
+	var paths [][]string
+	for _, job := range util.TravisJobPaths(doc) {
+		path := append(job, "go")
+		if _, ok := doc.Values(path...); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,218 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Lines of context around the changes of a hunk
+const diffContext = 3
+
+// A line of a diff, op is ' ', '-' or '+'
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// Unified diff turning from into to, empty when they're equal. A nil from or
+// to is a file being added or deleted.
+func UnifiedDiff(name string, from, to []byte) string {
+	if from != nil && to != nil && bytes.Equal(from, to) {
+		return ""
+	}
+
+	var b bytes.Buffer
+	fromName, toName := "a/"+name, "b/"+name
+	if from == nil {
+		fromName = "/dev/null"
+	}
+	if to == nil {
+		toName = "/dev/null"
+	}
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", fromName, toName)
+
+	lines := diffLines(splitLines(from), splitLines(to))
+	for _, h := range hunks(lines) {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.fromStart, h.fromLen), hunkRange(h.toStart, h.toLen))
+		for _, l := range lines[h.start:h.end] {
+			b.WriteByte(l.op)
+			b.WriteString(strings.TrimSuffix(l.text, "\n"))
+			b.WriteByte('\n')
+			if !strings.HasSuffix(l.text, "\n") {
+				b.WriteString("\\ No newline at end of file\n")
+			}
+		}
+	}
+	return b.String()
+}
+
+// Lines keep their newline so a missing one at the end is a change
+func splitLines(content []byte) []string {
+	if len(content) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// The common prefix and suffix are split off, the rest is diffed with
+// Myers' algorithm
+func diffLines(a, b []string) []diffLine {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []diffLine
+	for _, text := range a[:prefix] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	lines = append(lines, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, text := range a[len(a)-suffix:] {
+		lines = append(lines, diffLine{' ', text})
+	}
+	return lines
+}
+
+func myers(a, b []string) []diffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	offset := max + 1
+	v := make([]int, 2*max+3)
+
+	// trace[d] holds v for k in [-d-1, d+1] before step d
+	var trace [][]int
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	var reversed []diffLine
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		vd := trace[d]
+		at := func(k int) int { return vd[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			reversed = append(reversed, diffLine{' ', a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, diffLine{'+', b[prevY]})
+			} else {
+				reversed = append(reversed, diffLine{'-', a[prevX]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	lines := make([]diffLine, len(reversed))
+	for i, l := range reversed {
+		lines[len(reversed)-1-i] = l
+	}
+	return lines
+}
+
+type hunk struct {
+	// lines of the diff in the hunk
+	start, end int
+	// first line and length in each file, 1 based
+	fromStart, fromLen int
+	toStart, toLen     int
+}
+
+// Changes closer than twice the context share a hunk
+func hunks(lines []diffLine) []hunk {
+	var hs []hunk
+	fromLine, toLine := 1, 1
+	for i := 0; i < len(lines); {
+		if lines[i].op == ' ' {
+			fromLine++
+			toLine++
+			i++
+			continue
+		}
+
+		before := i
+		for before > 0 && i-before < diffContext {
+			before--
+		}
+		h := hunk{start: before, fromStart: fromLine - (i - before), toStart: toLine - (i - before)}
+		end, unchanged := i, 0
+		for j := i; j < len(lines) && unchanged <= 2*diffContext; j++ {
+			if lines[j].op == ' ' {
+				unchanged++
+				continue
+			}
+			unchanged = 0
+			end = j + 1
+		}
+		h.end = end + diffContext
+		if h.end > len(lines) {
+			h.end = len(lines)
+		}
+		for _, l := range lines[h.start:h.end] {
+			if l.op != '+' {
+				h.fromLen++
+			}
+			if l.op != '-' {
+				h.toLen++
+			}
+		}
+		for _, l := range lines[i:h.end] {
+			if l.op != '+' {
+				fromLine++
+			}
+			if l.op != '-' {
+				toLine++
+			}
+		}
+		hs = append(hs, h)
+		i = h.end
+	}
+	return hs
+}
+
+// An empty range is written as the line before it
+func hunkRange(start, length int) string {
+	if length == 0 {
+		start--
+	}
+	if length == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, length)
+}
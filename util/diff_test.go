@@ -0,0 +1,198 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// numbered lines from..to, each ending in a newline
+func numbered(from, to int) []string {
+	var lines []string
+	for i := from; i <= to; i++ {
+		lines = append(lines, fmt.Sprintf("line %02d", i))
+	}
+	return lines
+}
+
+func content(lines ...string) []byte {
+	if len(lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
+
+func replace(lines []string, i int, with ...string) []string {
+	out := append([]string{}, lines[:i]...)
+	out = append(out, with...)
+	return append(out, lines[i+1:]...)
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	twenty := numbered(1, 20)
+	cases := []struct {
+		name     string
+		from, to []byte
+		want     string
+	}{
+		{"equal", content(twenty...), content(twenty...), ""},
+		{
+			"changed line",
+			content(twenty...),
+			content(replace(twenty, 9, "line ten")...),
+			`--- a/f
++++ b/f
+@@ -7,7 +7,7 @@
+ line 07
+ line 08
+ line 09
+-line 10
++line ten
+ line 11
+ line 12
+ line 13
+`,
+		},
+		{
+			"added lines",
+			content("a", "b"),
+			content("a", "x", "y", "b"),
+			`--- a/f
++++ b/f
+@@ -1,2 +1,4 @@
+ a
++x
++y
+ b
+`,
+		},
+		{
+			"deleted lines",
+			content("a", "x", "y", "b"),
+			content("a", "b"),
+			`--- a/f
++++ b/f
+@@ -1,4 +1,2 @@
+ a
+-x
+-y
+ b
+`,
+		},
+		{
+			"new file",
+			nil,
+			content("a", "b"),
+			`--- /dev/null
++++ b/f
+@@ -0,0 +1,2 @@
++a
++b
+`,
+		},
+		{
+			"deleted file",
+			content("a"),
+			nil,
+			`--- a/f
++++ /dev/null
+@@ -1 +0,0 @@
+-a
+`,
+		},
+		{
+			"emptied file",
+			content("a", "b"),
+			content(),
+			`--- a/f
++++ b/f
+@@ -1,2 +0,0 @@
+-a
+-b
+`,
+		},
+		{
+			"newline added at end",
+			[]byte("a\nb"),
+			[]byte("a\nb\n"),
+			`--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+ a
+-b
+\ No newline at end of file
++b
+`,
+		},
+		{
+			"unchanged line without newline",
+			[]byte("a\nb"),
+			[]byte("x\nb"),
+			`--- a/f
++++ b/f
+@@ -1,2 +1,2 @@
+-a
++x
+ b
+\ No newline at end of file
+`,
+		},
+		{
+			// 6 unchanged lines between the changes are the context of both
+			"merged hunks",
+			content(twenty...),
+			content(replace(replace(twenty, 12, "line thirteen"), 5, "line six")...),
+			`--- a/f
++++ b/f
+@@ -3,14 +3,14 @@
+ line 03
+ line 04
+ line 05
+-line 06
++line six
+ line 07
+ line 08
+ line 09
+ line 10
+ line 11
+ line 12
+-line 13
++line thirteen
+ line 14
+ line 15
+ line 16
+`,
+		},
+		{
+			"separate hunks",
+			content(twenty...),
+			content(replace(replace(twenty, 16, "line seventeen"), 1)...),
+			`--- a/f
++++ b/f
+@@ -1,5 +1,4 @@
+ line 01
+-line 02
+ line 03
+ line 04
+ line 05
+@@ -14,7 +13,7 @@
+ line 14
+ line 15
+ line 16
+-line 17
++line seventeen
+ line 18
+ line 19
+ line 20
+`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := UnifiedDiff("f", c.from, c.to); diff != c.want {
+				t.Errorf("UnifiedDiff =\n%s\nwant\n%s", diff, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Edits an upgrade makes to a provider's files, written in place unless
+// DryRun is set, then they're kept in memory. Either way the original
+// content is kept to diff against.
+type Stage struct {
+	DryRun   bool
+	original map[string][]byte
+	written  map[string][]byte
+	order    []string
+}
+
+func NewStage(dryRun bool) *Stage {
+	return &Stage{
+		DryRun:   dryRun,
+		original: make(map[string][]byte),
+		written:  make(map[string][]byte),
+	}
+}
+
+// Reads a file with the edits staged so far
+func (s *Stage) ReadFile(filename string) ([]byte, error) {
+	if content, ok := s.written[filename]; ok {
+		return content, nil
+	}
+	return ioutil.ReadFile(filename)
+}
+
+func (s *Stage) WriteFile(filename string, content []byte) error {
+	if _, ok := s.original[filename]; !ok {
+		original, err := ioutil.ReadFile(filename)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		s.original[filename] = original
+		s.order = append(s.order, filename)
+	}
+	s.written[filename] = content
+	if s.DryRun {
+		return nil
+	}
+	return ioutil.WriteFile(filename, content, 0644)
+}
+
+// Runs a command, for a dry run it's only printed
+func (s *Stage) Run(env []string, dir, name string, arg ...string) error {
+	if s.DryRun {
+		os.Stderr.WriteString(fmt.Sprintf("==> (dry run) %s %s\n", name, strings.Join(arg, " ")))
+		return nil
+	}
+	return Run(env, dir, name, arg...)
+}
+
+// Unified diffs of the files written, or of filenames only, named relative
+// to root
+func (s *Stage) Diff(w io.Writer, root string, filenames ...string) error {
+	if len(filenames) == 0 {
+		filenames = s.order
+	}
+	for _, filename := range filenames {
+		original, ok := s.original[filename]
+		if !ok {
+			continue
+		}
+		name, err := filepath.Rel(root, filename)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, UnifiedDiff(filepath.ToSlash(name), original, s.written[filename])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Directory to run commands changing the provider in, the provider itself or
+// for a dry run a scratch copy of it. done removes the copy.
+func (s *Stage) WorkPath(providerPath string) (workPath string, done func(), err error) {
+	if !s.DryRun {
+		return providerPath, func() {}, nil
+	}
+	scratch, err := ScratchCopy(providerPath)
+	if err != nil {
+		return "", nil, err
+	}
+	os.Stderr.WriteString(fmt.Sprintf("==> (dry run) working in %s, %s is left untouched\n", scratch, providerPath))
+	return scratch, func() { os.RemoveAll(filepath.Dir(scratch)) }, nil
+}
+
+// Copies the provider into a temporary directory of the same name, for dry
+// runs of upgrades that shell out. .git is left out. Remove the parent of
+// the returned directory when done.
+func ScratchCopy(providerPath string) (string, error) {
+	tmp, err := ioutil.TempDir("", "tfplugin-dry-run")
+	if err != nil {
+		return "", err
+	}
+	scratch := filepath.Join(tmp, filepath.Base(providerPath))
+	err = filepath.Walk(providerPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(providerPath, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(scratch, rel)
+		switch {
+		case info.IsDir() && info.Name() == ".git":
+			return filepath.SkipDir
+		case info.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(target, content, info.Mode().Perm())
+	})
+	if err != nil {
+		os.RemoveAll(tmp)
+		return "", err
+	}
+	return scratch, nil
+}
+
+// Unified diffs of the files that differ between the trees, .git is
+// skipped and vendor/ is summarized by how many files changed
+func DiffTrees(w io.Writer, from, to string) error {
+	fromFiles, err := treeFiles(from)
+	if err != nil {
+		return err
+	}
+	toFiles, err := treeFiles(to)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]bool)
+	for name := range fromFiles {
+		names[name] = true
+	}
+	for name := range toFiles {
+		names[name] = true
+	}
+	var sorted []string
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var added, deleted, changed int
+	for _, name := range sorted {
+		a, b := fromFiles[name], toFiles[name]
+		if a != nil && b != nil && bytes.Equal(a, b) {
+			continue
+		}
+		if strings.HasPrefix(name, "vendor/") {
+			switch {
+			case a == nil:
+				added++
+			case b == nil:
+				deleted++
+			default:
+				changed++
+			}
+			continue
+		}
+		diff := UnifiedDiff(name, a, b)
+		if bytes.IndexByte(a, 0) >= 0 || bytes.IndexByte(b, 0) >= 0 {
+			diff = fmt.Sprintf("Binary files a/%s and b/%s differ\n", name, name)
+		}
+		if _, err := io.WriteString(w, diff); err != nil {
+			return err
+		}
+	}
+	if added+deleted+changed > 0 {
+		_, err := fmt.Fprintf(w, "vendor/: %d files added, %d deleted, %d changed\n", added, deleted, changed)
+		return err
+	}
+	return nil
+}
+
+// Contents of the regular files of a tree by their slash separated path
+func treeFiles(root string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && info.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = content
+		return nil
+	})
+	return files, err
+}
@@ -0,0 +1,183 @@
+package util
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Writes files by their slash separated path under a new temporary directory
+func writeTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "tfplugin-util")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiffTrees(t *testing.T) {
+	from := writeTree(t, map[string]string{
+		"main.go":                  "package main\n",
+		"go.mod":                   "module x\n\ngo 1.12\n",
+		"Gopkg.lock":               "lock\n",
+		"logo.png":                 "\x89PNG\x00old",
+		".git/HEAD":                "ref: refs/heads/master\n",
+		"vendor/a/a.go":            "package a\n",
+		"vendor/b/b.go":            "package b\n",
+		"vendor/c/c.go":            "package c\n",
+		"vendor/github.com/d/d.go": "package d\n",
+	})
+	defer os.RemoveAll(from)
+	to := writeTree(t, map[string]string{
+		"main.go":                  "package main\n",
+		"go.mod":                   "module x\n\ngo 1.14\n",
+		"go.sum":                   "sum\n",
+		"logo.png":                 "\x89PNG\x00new",
+		".git/HEAD":                "ref: refs/heads/modules\n",
+		"vendor/a/a.go":            "package a\n",
+		"vendor/b/b.go":            "package b // changed\n",
+		"vendor/e/e.go":            "package e\n",
+		"vendor/f/f.go":            "package f\n",
+		"vendor/github.com/d/d.go": "package d // changed\n",
+	})
+	defer os.RemoveAll(to)
+
+	var b bytes.Buffer
+	if err := DiffTrees(&b, from, to); err != nil {
+		t.Fatal(err)
+	}
+	want := `--- a/Gopkg.lock
++++ /dev/null
+@@ -1 +0,0 @@
+-lock
+--- a/go.mod
++++ b/go.mod
+@@ -1,3 +1,3 @@
+ module x
+ 
+-go 1.12
++go 1.14
+--- /dev/null
++++ b/go.sum
+@@ -0,0 +1 @@
++sum
+Binary files a/logo.png and b/logo.png differ
+vendor/: 2 files added, 1 deleted, 2 changed
+`
+	if b.String() != want {
+		t.Errorf("DiffTrees =\n%s\nwant\n%s", b.String(), want)
+	}
+
+	b.Reset()
+	if err := DiffTrees(&b, from, from); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("DiffTrees of a tree with itself =\n%s", b.String())
+	}
+}
+
+func TestScratchCopy(t *testing.T) {
+	provider := writeTree(t, map[string]string{
+		"main.go":   "package main\n",
+		"x/x.go":    "package x\n",
+		".git/HEAD": "ref: refs/heads/master\n",
+	})
+	defer os.RemoveAll(provider)
+	if err := os.Symlink("x/x.go", filepath.Join(provider, "link.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	stage := NewStage(true)
+	scratch, done, err := stage.WorkPath(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scratch == provider || filepath.Base(scratch) != filepath.Base(provider) {
+		t.Errorf("scratch copy %s of %s", scratch, provider)
+	}
+	if _, err := os.Stat(filepath.Join(scratch, ".git")); !os.IsNotExist(err) {
+		t.Errorf(".git was copied: %v", err)
+	}
+	if link, err := os.Readlink(filepath.Join(scratch, "link.go")); err != nil || link != "x/x.go" {
+		t.Errorf("link.go = %q, %v", link, err)
+	}
+	var b bytes.Buffer
+	if err := DiffTrees(&b, provider, scratch); err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 0 {
+		t.Errorf("scratch copy differs:\n%s", b.String())
+	}
+
+	done()
+	if _, err := os.Stat(filepath.Dir(scratch)); !os.IsNotExist(err) {
+		t.Errorf("scratch copy left behind: %v", err)
+	}
+
+	workPath, done, err := NewStage(false).WorkPath(provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done()
+	if workPath != provider {
+		t.Errorf("WorkPath = %s, want the provider %s", workPath, provider)
+	}
+}
+
+func TestStage(t *testing.T) {
+	dir := writeTree(t, map[string]string{"a.txt": "a\n"})
+	defer os.RemoveAll(dir)
+	a, b := filepath.Join(dir, "a.txt"), filepath.Join(dir, "b.txt")
+
+	for _, dryRun := range []bool{true, false} {
+		stage := NewStage(dryRun)
+		if err := stage.WriteFile(a, []byte("a\nchanged\n")); err != nil {
+			t.Fatal(err)
+		}
+		if err := stage.WriteFile(b, []byte("b\n")); err != nil {
+			t.Fatal(err)
+		}
+		// the staged content is read back
+		if content, err := stage.ReadFile(a); err != nil || string(content) != "a\nchanged\n" {
+			t.Errorf("ReadFile = %q, %v", content, err)
+		}
+
+		var diff bytes.Buffer
+		if err := stage.Diff(&diff, dir); err != nil {
+			t.Fatal(err)
+		}
+		want := `--- a/a.txt
++++ b/a.txt
+@@ -1 +1,2 @@
+ a
++changed
+--- /dev/null
++++ b/b.txt
+@@ -0,0 +1 @@
++b
+`
+		if diff.String() != want {
+			t.Errorf("dry run %t Diff =\n%s\nwant\n%s", dryRun, diff.String(), want)
+		}
+
+		content, _ := ioutil.ReadFile(a)
+		if dryRun && string(content) != "a\n" {
+			t.Errorf("dry run wrote %s", a)
+		}
+		if !dryRun && string(content) != "a\nchanged\n" {
+			t.Errorf("%s was not written", a)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package util
+
+import (
+	"strconv"
+
+	"github.com/appilon/tfplugin/util/yamledit"
+)
+
+// Reads .travis.yml or .travis.yaml of the provider
+func ReadTravis(providerPath string) (string, *yamledit.Document, error) {
+	filename, content, err := ReadOneOf(providerPath, ".travis.yml", ".travis.yaml")
+	if err != nil {
+		return "", nil, err
+	}
+	return filename, yamledit.Parse(content), nil
+}
+
+// Paths where a job's go, env and build steps can be set: the top level and
+// each matrix or jobs include entry
+func TravisJobPaths(doc *yamledit.Document) [][]string {
+	paths := [][]string{{}}
+	for _, key := range []string{"matrix", "jobs"} {
+		for i := 0; i < doc.Len(key, "include"); i++ {
+			paths = append(paths, []string{key, "include", strconv.Itoa(i)})
+		}
+	}
+	return paths
+}
+
+func IsGoTravis(doc *yamledit.Document) bool {
+	language, _ := doc.Values("language")
+	return len(language) == 1 && language[0] == "go"
+}
@@ -1,19 +1,24 @@
 package util
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/radeksimko/go-mod-diff/go-src/cmd/go/_internal/modfile"
 )
 
 const (
 	providerPrefix    = "terraform-provider-"
 	provisionerPrefix = "terraform-provisioner-"
+	sdkModule         = "github.com/hashicorp/terraform"
 )
 
 func FindProvider(providerPath string) (string, error) {
@@ -21,6 +26,22 @@ func FindProvider(providerPath string) (string, error) {
 		return os.Getwd()
 	}
 
+	// providers on go modules can live outside of GOPATH
+	if filepath.IsAbs(providerPath) || strings.HasPrefix(providerPath, ".") {
+		info, err := os.Stat(providerPath)
+		if err != nil {
+			return "", err
+		}
+		if !info.IsDir() {
+			return "", fmt.Errorf("%s is not a directory", providerPath)
+		}
+		return filepath.Abs(providerPath)
+	}
+
+	return FindInGopath(providerPath)
+}
+
+func FindInGopath(importPath string) (string, error) {
 	gopath := os.Getenv("GOPATH")
 	if gopath == "" {
 		return "", errors.New("GOPATH is empty")
@@ -28,7 +49,7 @@ func FindProvider(providerPath string) (string, error) {
 	gopaths := filepath.SplitList(gopath)
 
 	for _, p := range gopaths {
-		fullPath := filepath.Join(p, "src", providerPath)
+		fullPath := filepath.Join(p, "src", importPath)
 		info, err := os.Stat(fullPath)
 
 		if err == nil {
@@ -42,12 +63,125 @@ func FindProvider(providerPath string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("Could not find %s in GOPATH: %s", providerPath, gopath)
+	return "", fmt.Errorf("Could not find %s in GOPATH: %s", importPath, gopath)
+}
+
+// Import path of a directory inside of GOPATH
+func GopathImportPath(fullPath string) (string, error) {
+	for _, p := range filepath.SplitList(os.Getenv("GOPATH")) {
+		src := filepath.Join(p, "src") + string(filepath.Separator)
+		if strings.HasPrefix(fullPath, src) {
+			return filepath.ToSlash(strings.TrimPrefix(fullPath, src)), nil
+		}
+	}
+	return "", fmt.Errorf("%s is not in GOPATH: %s", fullPath, os.Getenv("GOPATH"))
+}
+
+// Import path of a provider, the module path for providers on go modules
+func ImportPath(providerPath string) (string, error) {
+	f, err := ReadGoMod(providerPath)
+	if os.IsNotExist(err) {
+		return GopathImportPath(providerPath)
+	} else if err != nil {
+		return "", err
+	}
+	if f.Module == nil {
+		return "", fmt.Errorf("%s/go.mod has no module statement", providerPath)
+	}
+	return f.Module.Mod.Path, nil
+}
+
+// Parses the go.mod file of a provider, returns an os.IsNotExist error if
+// the provider is not on go modules
+func ReadGoMod(providerPath string) (*modfile.File, error) {
+	gomodPath := filepath.Join(providerPath, "go.mod")
+	data, err := ioutil.ReadFile(gomodPath)
+	if err != nil {
+		return nil, err
+	}
+	return modfile.Parse(gomodPath, data, nil)
+}
+
+// Version of the Terraform SDK the provider builds against, read from
+// go.mod, vendor/modules.txt or a govendor vendor/vendor.json
+func SDKVersion(providerPath string) (string, error) {
+	if f, err := ReadGoMod(providerPath); err == nil {
+		for _, r := range f.Require {
+			if r.Mod.Path == sdkModule {
+				return r.Mod.Version, nil
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if content, err := ioutil.ReadFile(filepath.Join(providerPath, "vendor", "modules.txt")); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 3 && fields[0] == "#" && fields[1] == sdkModule {
+				return fields[2], nil
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if content, err := ioutil.ReadFile(filepath.Join(providerPath, "vendor", "vendor.json")); err == nil {
+		var manifest struct {
+			Package []struct {
+				Path         string
+				Revision     string
+				Version      string
+				VersionExact string
+			}
+		}
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			return "", err
+		}
+		for _, p := range manifest.Package {
+			if p.Path != sdkModule && !strings.HasPrefix(p.Path, sdkModule+"/") {
+				continue
+			}
+			if p.VersionExact != "" {
+				return p.VersionExact, nil
+			}
+			if p.Version != "" {
+				return p.Version, nil
+			}
+			return p.Revision, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	return "", fmt.Errorf("%s does not depend on %s", providerPath, sdkModule)
+}
+
+// Runs git in dir and returns its output without the trailing newline
+func Git(dir string, arg ...string) (string, error) {
+	cmd := exec.Command("git", arg...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// Module paths of v2 and later plugins end in their major version
+// ex: github.com/terraform-providers/terraform-provider-aws/v2
+var majorVersionSuffix = regexp.MustCompile(`/v\d+$`)
+
+// Last segment of a plugin's path, skipping a major version suffix
+func pluginName(pluginPath string) string {
+	segments := strings.Split(majorVersionSuffix.ReplaceAllString(pluginPath, ""), "/")
+	return segments[len(segments)-1]
+}
+
+// Reports whether the plugin follows the terraform-provisioner-{name} convention
+func IsProvisioner(pluginPath string) bool {
+	return strings.HasPrefix(pluginName(pluginPath), provisionerPrefix)
 }
 
 func GetPackageName(providerPath string) (string, error) {
-	segments := strings.Split(providerPath, "/")
-	last := segments[len(segments)-1]
+	last := pluginName(providerPath)
 	var prefix string
 	if strings.HasPrefix(last, providerPrefix) {
 		prefix = providerPrefix
@@ -79,6 +213,16 @@ func Run(env []string, dir, name string, arg ...string) error {
 	return cmd.Run()
 }
 
+// Like Run but returns stdout instead of passing it through
+func Output(env []string, dir, name string, arg ...string) ([]byte, error) {
+	os.Stderr.WriteString(fmt.Sprintf("==> %s %s\n", name, strings.Join(arg, " ")))
+	cmd := exec.Command(name, arg...)
+	cmd.Dir = dir
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	return cmd.Output()
+}
+
 func ReadOneOf(dir string, filenames ...string) (fullpath string, content []byte, err error) {
 	for _, filename := range filenames {
 		fullpath = filepath.Join(dir, filename)
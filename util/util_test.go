@@ -0,0 +1,94 @@
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetPackageName(t *testing.T) {
+	cases := []struct {
+		path, name  string
+		provisioner bool
+	}{
+		{"github.com/terraform-providers/terraform-provider-aws", "aws", false},
+		{"github.com/terraform-providers/terraform-provider-aws/v2", "aws", false},
+		{"github.com/terraform-providers/terraform-provider-aws/v12", "aws", false},
+		{"github.com/acme/terraform-provisioner-ansible/v3", "ansible", true},
+		{"/home/dev/src/terraform-provider-netlify", "netlify", false},
+		{"terraform-provider-netlify_v0.1.0", "netlify_v0.1.0", false},
+		{"terraform-provider-v2", "v2", false},
+		{"github.com/acme/terraform-provider-x/version", "", false},
+		{"github.com/acme/tfplugin/v2", "", false},
+		{"v2", "", false},
+	}
+	for _, c := range cases {
+		name, err := GetPackageName(c.path)
+		if c.name == "" {
+			if err == nil {
+				t.Errorf("GetPackageName(%q) = %q, want an error", c.path, name)
+			}
+		} else if err != nil || name != c.name {
+			t.Errorf("GetPackageName(%q) = %q, %v, want %q", c.path, name, err, c.name)
+		}
+		if IsProvisioner(c.path) != c.provisioner {
+			t.Errorf("IsProvisioner(%q) = %t", c.path, !c.provisioner)
+		}
+	}
+}
+
+func TestImportPath(t *testing.T) {
+	cases := []struct {
+		name, gomod, want, pkg string
+	}{
+		{"module", "module github.com/terraform-providers/terraform-provider-aws\n", "github.com/terraform-providers/terraform-provider-aws", "aws"},
+		{"major version", "module github.com/terraform-providers/terraform-provider-aws/v2\n\ngo 1.13\n", "github.com/terraform-providers/terraform-provider-aws/v2", "aws"},
+		{"quoted", "module \"github.com/acme/terraform-provider-x/v3\"\n", "github.com/acme/terraform-provider-x/v3", "x"},
+		{"no module", "go 1.13\n", "", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := writeTree(t, map[string]string{"go.mod": c.gomod})
+			defer os.RemoveAll(dir)
+
+			importPath, err := ImportPath(dir)
+			if c.want == "" {
+				if err == nil {
+					t.Errorf("ImportPath = %q, want an error", importPath)
+				}
+				return
+			}
+			if err != nil || importPath != c.want {
+				t.Fatalf("ImportPath = %q, %v, want %q", importPath, err, c.want)
+			}
+			if name, err := GetPackageName(importPath); err != nil || name != c.pkg {
+				t.Errorf("GetPackageName(%q) = %q, %v, want %q", importPath, name, err, c.pkg)
+			}
+		})
+	}
+}
+
+// Outside of go modules the import path is where the provider is in GOPATH
+func TestImportPathGopath(t *testing.T) {
+	gopath, err := ioutil.TempDir("", "tfplugin-gopath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(gopath)
+	provider := filepath.Join(gopath, "src", "github.com", "acme", "terraform-provider-x")
+	if err := os.MkdirAll(provider, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Setenv("GOPATH", os.Getenv("GOPATH"))
+	os.Setenv("GOPATH", gopath)
+	importPath, err := ImportPath(provider)
+	if err != nil || importPath != "github.com/acme/terraform-provider-x" {
+		t.Errorf("ImportPath = %q, %v", importPath, err)
+	}
+
+	if _, err := ImportPath(os.TempDir()); err == nil {
+		t.Error("ImportPath outside of GOPATH succeeded")
+	}
+}
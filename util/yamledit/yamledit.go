@@ -0,0 +1,786 @@
+package yamledit
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// A YAML document edited line by line, everything outside the values being
+// changed (comments, blank lines, quoting, key order) is kept as written.
+// Block mappings and sequences, inline scalars and flow collections written
+// on one line are understood, which covers CI configs. A flow collection a
+// path leads into is rewritten as a block one.
+type Document struct {
+	// without their line endings
+	lines []string
+	// \n or \r\n, lines added use the document's
+	eol string
+	// whether the last line ends with eol
+	final bool
+}
+
+// Kinds of values
+const (
+	KindScalar   = "scalar"
+	KindSequence = "sequence"
+	KindMapping  = "mapping"
+)
+
+// key: value, key may be quoted
+var keyRegexp = regexp.MustCompile(`^("[^"]*"|'[^']*'|[^\s#'"\-\[{][^:#]*?|-[^\s:#][^:#]*?)\s*:(\s|$)`)
+
+// A document with a \r\n line ending is written back with \r\n only. An
+// empty one ends with a newline once something is added.
+func Parse(content []byte) *Document {
+	text := string(content)
+	d := &Document{eol: "\n", final: true}
+	if strings.Contains(text, "\r\n") {
+		d.eol = "\r\n"
+		text = strings.Replace(text, "\r\n", "\n", -1)
+	}
+	if text == "" {
+		return d
+	}
+	d.final = strings.HasSuffix(text, "\n")
+	d.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	return d
+}
+
+func (d *Document) Bytes() []byte {
+	out := strings.Join(d.lines, d.eol)
+	if d.final && len(d.lines) > 0 {
+		out += d.eol
+	}
+	return []byte(out)
+}
+
+// A mapping entry or sequence item and the lines it spans
+type node struct {
+	// empty for sequence items
+	key  string
+	line int
+	// column of the key or dash, and of what follows the dash
+	indent int
+	col    int
+	// inline value without its comment, valueStart and valueEnd are its
+	// columns, a sequence item holding a mapping has the first key here
+	value      string
+	valueStart int
+	valueEnd   int
+	// end of the lines belonging to the node, trailing comments excluded
+	end int
+}
+
+func (n *node) item() bool {
+	return n.key == ""
+}
+
+// A sequence item starting with key: value
+func (n *node) mappingItem() bool {
+	return n.item() && keyRegexp.MatchString(n.value)
+}
+
+func (n *node) kind(children []*node) string {
+	switch {
+	case n.mappingItem():
+		return KindMapping
+	case strings.HasPrefix(n.value, "["):
+		return KindSequence
+	case strings.HasPrefix(n.value, "{"):
+		return KindMapping
+	case n.value != "":
+		return KindScalar
+	case len(children) == 0:
+		return ""
+	case children[0].item():
+		return KindSequence
+	}
+	return KindMapping
+}
+
+// Kind of the value at path, empty when missing or null
+func (d *Document) Kind(path ...string) string {
+	d = d.expanded(path)
+	n := d.find(path)
+	if n == nil {
+		return ""
+	}
+	return n.kind(d.children(n))
+}
+
+// The scalar, or the scalars of the sequence, at path. The lines of a
+// block scalar are joined by newlines without their indentation.
+func (d *Document) Values(path ...string) ([]string, bool) {
+	return d.values(path, false)
+}
+
+// Like Values with quotes kept, for values that are written back
+func (d *Document) RawValues(path ...string) ([]string, bool) {
+	return d.values(path, true)
+}
+
+func (d *Document) values(path []string, raw bool) ([]string, bool) {
+	d = d.expanded(path)
+	n := d.find(path)
+	if n == nil {
+		return nil, false
+	}
+	value := func(v string) string {
+		if raw {
+			return v
+		}
+		return unquote(v)
+	}
+	children := d.children(n)
+	switch n.kind(children) {
+	case KindScalar:
+		if blockScalar(n.value) {
+			return []string{d.blockContent(n)}, true
+		}
+		return []string{value(n.value)}, true
+	case KindSequence:
+		var values []string
+		if strings.HasPrefix(n.value, "[") {
+			for _, v := range rawFlowValues(n.value) {
+				values = append(values, value(v))
+			}
+			return values, true
+		}
+		for _, c := range children {
+			if blockScalar(c.value) {
+				values = append(values, d.blockContent(c))
+			} else {
+				values = append(values, value(c.value))
+			}
+		}
+		return values, true
+	}
+	return nil, true
+}
+
+// Keys of the mapping at path in the order they're written
+func (d *Document) Keys(path ...string) []string {
+	d = d.expanded(path)
+	var nodes []*node
+	if len(path) == 0 {
+		nodes = d.entries(0, len(d.lines), -1)
+	} else if n := d.find(path); n != nil {
+		d.expand(n)
+		nodes = d.children(d.find(path))
+	}
+	var keys []string
+	for _, n := range nodes {
+		if !n.item() {
+			keys = append(keys, n.key)
+		}
+	}
+	return keys
+}
+
+// Number of items in the sequence at path, items are selected by their
+// index in paths
+func (d *Document) Len(path ...string) int {
+	d = d.expanded(path)
+	n := d.find(path)
+	if n == nil || n.kind(d.children(n)) != KindSequence {
+		return 0
+	}
+	if strings.HasPrefix(n.value, "[") {
+		return len(rawFlowValues(n.value))
+	}
+	return len(d.children(n))
+}
+
+// Sets the value at path, creating the keys leading to it. values are
+// written as given, quoting is up to the caller. A single value is written
+// as a scalar unless the existing value is a sequence.
+func (d *Document) SetValues(values []string, path ...string) {
+	n := d.ensure(path)
+	children := d.children(n)
+	switch n.kind(children) {
+	case KindScalar:
+		if len(values) == 1 {
+			// the lines of a block scalar go with it
+			d.deleteLines(n.line+1, n.end)
+			d.replaceValue(n, values[0])
+			return
+		}
+	case KindSequence:
+		if strings.HasPrefix(n.value, "[") {
+			d.replaceValue(n, "["+strings.Join(values, ", ")+"]")
+			return
+		}
+	}
+
+	// replaced by a block value, the indentation of existing items is kept
+	indent := n.indent + 2
+	if len(children) > 0 && children[0].item() {
+		indent = children[0].indent
+	}
+	if len(values) == 1 && len(children) == 0 {
+		d.replaceValue(n, values[0])
+		return
+	}
+	d.replaceValue(n, "")
+	d.deleteLines(n.line+1, n.end)
+	var lines []string
+	for _, v := range values {
+		lines = append(lines, strings.Repeat(" ", indent)+"- "+v)
+	}
+	d.insertLines(n.line+1, lines...)
+}
+
+// Appends value to the sequence at path, a scalar becomes a sequence of
+// the old and the new value
+func (d *Document) Append(value string, path ...string) {
+	n := d.ensure(path)
+	children := d.children(n)
+	switch n.kind(children) {
+	case KindScalar:
+		old := n.value
+		d.replaceValue(n, "")
+		indent := strings.Repeat(" ", n.indent+2)
+		// the lines of a block scalar move under its item
+		d.shift(n.line+1, n.end)
+		d.insertLines(n.end, indent+"- "+value)
+		d.insertLines(n.line+1, indent+"- "+old)
+	case KindSequence:
+		if strings.HasPrefix(n.value, "[") {
+			d.replaceValue(n, "["+strings.Join(append(rawFlowValues(n.value), value), ", ")+"]")
+			return
+		}
+		last := children[len(children)-1]
+		d.insertLines(last.end, strings.Repeat(" ", last.indent)+"- "+value)
+	default:
+		d.insertLines(n.line+1, strings.Repeat(" ", n.indent+2)+"- "+value)
+	}
+}
+
+// Removes the items of the sequence at path, or the scalar, match is true
+// for. Items are matched on all of their lines. The key goes when nothing
+// is left. Returns how many values were removed.
+func (d *Document) RemoveItems(match func(string) bool, path ...string) int {
+	// flow collections are only rewritten when something is removed
+	c := d.expanded(path)
+	removed := c.removeItems(match, path)
+	if removed > 0 {
+		c.prune(path[:len(path)-1])
+		d.lines = c.lines
+	}
+	return removed
+}
+
+func (d *Document) removeItems(match func(string) bool, path []string) int {
+	n := d.find(path)
+	if n == nil {
+		return 0
+	}
+	children := d.children(n)
+	switch n.kind(children) {
+	case KindScalar:
+		text := unquote(n.value)
+		if blockScalar(n.value) {
+			text = d.blockContent(n)
+		}
+		if !match(text) {
+			return 0
+		}
+		d.remove(n)
+		return 1
+	case KindSequence:
+		if strings.HasPrefix(n.value, "[") {
+			var kept []string
+			for _, v := range rawFlowValues(n.value) {
+				if !match(unquote(v)) {
+					kept = append(kept, v)
+				}
+			}
+			removed := len(rawFlowValues(n.value)) - len(kept)
+			if len(kept) == 0 {
+				d.remove(n)
+			} else if removed > 0 {
+				d.replaceValue(n, "["+strings.Join(kept, ", ")+"]")
+			}
+			return removed
+		}
+		removed := 0
+		for i := len(children) - 1; i >= 0; i-- {
+			c := children[i]
+			text := strings.Join(d.lines[c.line:c.end], "\n")
+			text = strings.TrimSpace(text[c.indent+1:])
+			if match(text) {
+				d.deleteLines(c.line, c.end)
+				removed++
+			}
+		}
+		if removed == len(children) {
+			d.remove(d.find(path))
+		}
+		return removed
+	}
+	return 0
+}
+
+// Moves the value at path under a new key, ex: env: [A] becomes
+// env: {matrix: [A]}
+func (d *Document) Nest(key string, path ...string) {
+	d.expandPath(path)
+	n := d.find(path)
+	if n == nil {
+		return
+	}
+	indent := strings.Repeat(" ", n.indent+2)
+	d.shift(n.line+1, n.end)
+	if n.value != "" {
+		value := n.value
+		d.replaceValue(n, "")
+		d.insertLines(n.line+1, indent+key+": "+value)
+		return
+	}
+	d.insertLines(n.line+1, indent+key+":")
+}
+
+// Finds path from the top level, numbers select sequence items
+func (d *Document) find(path []string) *node {
+	nodes := d.entries(0, len(d.lines), -1)
+	var n *node
+	for _, p := range path {
+		n = nil
+		for _, c := range nodes {
+			if c.item() {
+				if i, err := strconv.Atoi(p); err == nil && i < len(nodes) {
+					n = nodes[i]
+				}
+				break
+			}
+			if c.key == p {
+				n = c
+				break
+			}
+		}
+		if n == nil {
+			return nil
+		}
+		nodes = d.children(n)
+	}
+	return n
+}
+
+// Like find, missing keys are added to the end of their mapping
+func (d *Document) ensure(path []string) *node {
+	d.expandPath(path)
+	for i := range path {
+		if d.find(path[:i+1]) != nil {
+			continue
+		}
+		line, indent := len(d.lines), 0
+		if i == 0 {
+			line = d.trimEnd(0, len(d.lines))
+		} else {
+			parent := d.find(path[:i])
+			children := d.children(parent)
+			line, indent = parent.end, parent.indent+2
+			if parent.mappingItem() {
+				indent = parent.col
+			} else if len(children) > 0 {
+				indent = children[0].indent
+			} else if parent.value != "" {
+				// a scalar can't hold keys
+				d.replaceValue(parent, "")
+			}
+		}
+		d.insertLines(line, strings.Repeat(" ", indent)+path[i]+":")
+	}
+	return d.find(path)
+}
+
+// Removes a node, a key on the line of a sequence item's dash leaves the
+// dash to the key following it
+func (d *Document) remove(n *node) {
+	if n.indent > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[n.line][:n.indent]), "-") {
+		dash := d.lines[n.line][:n.indent]
+		d.deleteLines(n.line+1, n.end)
+		if next := n.line + 1; next < len(d.lines) && indentOf(d.lines[next]) == n.indent && !insignificant(d.lines[next]) {
+			d.lines[n.line] = dash + d.lines[next][n.indent:]
+			d.deleteLines(next, next+1)
+			return
+		}
+		d.lines[n.line] = strings.TrimRight(dash, " ")
+		return
+	}
+	d.deleteLines(n.line, n.end)
+}
+
+// Removes the keys and sequence items along path, deepest first, left
+// without a value by a removal below them
+func (d *Document) prune(path []string) {
+	for i := len(path); i > 0; i-- {
+		n := d.find(path[:i])
+		if n == nil || n.kind(d.children(n)) != "" {
+			return
+		}
+		if n.item() {
+			d.deleteLines(n.line, n.end)
+		} else {
+			d.remove(n)
+		}
+	}
+}
+
+// A copy with the flow collections along path rewritten, for reads that
+// leave the document as is
+func (d *Document) expanded(path []string) *Document {
+	c := &Document{lines: append([]string(nil), d.lines...), eol: d.eol, final: d.final}
+	c.expandPath(path)
+	return c
+}
+
+// Rewrites the flow collections path leads into as block ones
+func (d *Document) expandPath(path []string) {
+	for i := 1; i < len(path); i++ {
+		if d.find(path[:i+1]) != nil {
+			continue
+		}
+		n := d.find(path[:i])
+		if n == nil {
+			return
+		}
+		d.expand(n)
+	}
+}
+
+// Rewrites the flow collection of a node as a block one, the first key of a
+// mapping in a sequence goes on the line of the dash. Empty ones are kept.
+func (d *Document) expand(n *node) {
+	if !strings.HasPrefix(n.value, "[") && !strings.HasPrefix(n.value, "{") {
+		return
+	}
+	entries := rawFlowValues(n.value)
+	if len(entries) == 0 {
+		return
+	}
+	mapping := strings.HasPrefix(n.value, "{")
+	for i, e := range entries {
+		switch {
+		case !mapping:
+			entries[i] = "- " + e
+		case !keyRegexp.MatchString(e):
+			// {a, b} sets keys without values
+			entries[i] = e + ":"
+		}
+	}
+
+	indent := n.indent + 2
+	if mapping && n.item() {
+		d.replaceValue(n, entries[0])
+		entries, indent = entries[1:], n.col
+	} else {
+		d.replaceValue(n, "")
+	}
+	for i, e := range entries {
+		entries[i] = strings.Repeat(" ", indent) + e
+	}
+	d.insertLines(n.line+1, entries...)
+}
+
+func (d *Document) children(n *node) []*node {
+	switch {
+	case n.mappingItem():
+		return d.entries(n.line, n.end, n.col)
+	case n.value == "":
+		return d.entries(n.line+1, n.end, -1)
+	}
+	return nil
+}
+
+// The entries of the mapping or sequence in lines [start, end), when col is
+// not negative the first line starts at col
+func (d *Document) entries(start, end, col int) []*node {
+	var nodes []*node
+	indent := -1
+	sequence := false
+	for i := start; i < end; i++ {
+		c := indentOf(d.lines[i])
+		if i == start && col >= 0 {
+			c = col
+		}
+		if c >= len(d.lines[i]) {
+			continue
+		}
+		content := d.lines[i][c:]
+		if insignificant(content) {
+			continue
+		}
+		if indent == -1 {
+			indent = c
+			sequence = isItem(content)
+		}
+		// deeper lines belong to the previous entry, as do sequences at the
+		// indentation of the key holding them
+		if c != indent || sequence != isItem(content) {
+			continue
+		}
+
+		n := parseNode(i, c, content)
+		if n == nil {
+			continue
+		}
+		if len(nodes) > 0 {
+			prev := nodes[len(nodes)-1]
+			prev.end = d.trimEnd(prev.line, i)
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) > 0 {
+		last := nodes[len(nodes)-1]
+		last.end = d.trimEnd(last.line, end)
+	}
+	return nodes
+}
+
+func parseNode(line, indent int, content string) *node {
+	n := &node{line: line, indent: indent, col: indent}
+	if isItem(content) {
+		rest := content[1:]
+		n.col = indent + 1 + len(rest) - len(strings.TrimLeft(rest, " "))
+		n.value, n.valueStart, n.valueEnd = inlineValue(content, n.col-indent)
+	} else {
+		match := keyRegexp.FindStringSubmatch(content)
+		if match == nil {
+			return nil
+		}
+		n.key = unquote(strings.TrimSpace(match[1]))
+		n.value, n.valueStart, n.valueEnd = inlineValue(content, len(match[0]))
+	}
+	n.valueStart += indent
+	n.valueEnd += indent
+	return n
+}
+
+// The value starting at from with its comment stripped
+func inlineValue(content string, from int) (string, int, int) {
+	if from > len(content) {
+		from = len(content)
+	}
+	end := len(content)
+	var quote byte
+	for i := from; i < len(content); i++ {
+		switch ch := content[i]; {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '#' && (i == 0 || content[i-1] == ' '):
+			end = i
+			i = len(content)
+		}
+	}
+	start := from
+	for start < end && content[start] == ' ' {
+		start++
+	}
+	for end > start && content[end-1] == ' ' {
+		end--
+	}
+	return content[start:end], start, end
+}
+
+func (d *Document) replaceValue(n *node, value string) {
+	line := d.lines[n.line]
+	prefix := strings.TrimRight(line[:n.valueStart], " ")
+	if value != "" {
+		prefix += " " + value
+	}
+	rest := line[n.valueEnd:]
+	if strings.HasPrefix(rest, "#") {
+		rest = " " + rest
+	}
+	d.lines[n.line] = strings.TrimRight(prefix+rest, " ")
+}
+
+// The value of a | or > block scalar, folded and chomped the way its
+// header says
+func (d *Document) blockContent(n *node) string {
+	header := strings.Fields(n.value)[0]
+	chomping, indent := byte(0), -1
+	for _, ch := range header[1:] {
+		switch {
+		case ch == '-' || ch == '+':
+			chomping = byte(ch)
+		case ch >= '1' && ch <= '9':
+			indent = n.indent + int(ch-'0')
+		}
+	}
+
+	var lines []string
+	for _, line := range d.lines[n.line+1 : n.end] {
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if indent == -1 {
+			indent = indentOf(line)
+		}
+		if indentOf(line) < indent {
+			lines = append(lines, strings.TrimLeft(line, " "))
+		} else {
+			lines = append(lines, line[indent:])
+		}
+	}
+	// blank lines after the content end it, they only count when kept
+	for i := n.end; i < len(d.lines) && strings.TrimSpace(d.lines[i]) == ""; i++ {
+		lines = append(lines, "")
+	}
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := len(lines) - end
+	lines = lines[:end]
+
+	text := strings.Join(lines, "\n")
+	if header[0] == '>' {
+		text = fold(lines)
+	}
+	switch {
+	case chomping == '+':
+		if len(lines) > 0 {
+			trailing++
+		}
+		return text + strings.Repeat("\n", trailing)
+	case chomping == '-' || len(lines) == 0:
+		return text
+	}
+	return text + "\n"
+}
+
+// Joins the lines of a folded scalar, a line break between two lines is a
+// space. Empty lines are kept as newlines, as are the line breaks around
+// lines indented more than the rest.
+func fold(lines []string) string {
+	moreIndented := func(line string) bool {
+		return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+	}
+	var b strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case prev == "":
+				b.WriteString("\n")
+			case line != "" && !moreIndented(prev) && !moreIndented(line):
+				b.WriteString(" ")
+			case line == "" && !moreIndented(prev):
+				// the line break before empty lines is dropped unless a more
+				// indented line follows them
+				next := i
+				for next < len(lines) && lines[next] == "" {
+					next++
+				}
+				if next < len(lines) && moreIndented(lines[next]) {
+					b.WriteString("\n")
+				}
+			default:
+				b.WriteString("\n")
+			}
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// Indents lines [from, to) two more spaces
+func (d *Document) shift(from, to int) {
+	for i := from; i < to; i++ {
+		if strings.TrimSpace(d.lines[i]) != "" {
+			d.lines[i] = "  " + d.lines[i]
+		}
+	}
+}
+
+func (d *Document) insertLines(at int, lines ...string) {
+	d.lines = append(d.lines[:at], append(lines, d.lines[at:]...)...)
+}
+
+func (d *Document) deleteLines(from, to int) {
+	if to > from {
+		d.lines = append(d.lines[:from], d.lines[to:]...)
+	}
+}
+
+// Trailing blank and comment lines belong to whatever follows
+func (d *Document) trimEnd(start, end int) int {
+	for end > start+1 && insignificant(d.lines[end-1]) {
+		end--
+	}
+	return end
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func insignificant(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---"
+}
+
+func isItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+func blockScalar(value string) bool {
+	return strings.HasPrefix(value, "|") || strings.HasPrefix(value, ">")
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		if s[0] == '"' {
+			if u, err := strconv.Unquote(s); err == nil {
+				return u
+			}
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// Entries of a flow sequence or mapping, split at the commas outside of
+// quotes and nested collections
+func rawFlowValues(flow string) []string {
+	inner := strings.TrimSuffix(flow[1:], "]")
+	if flow[0] == '{' {
+		inner = strings.TrimSuffix(flow[1:], "}")
+	}
+	inner = strings.TrimSpace(inner)
+	var values []string
+	start, depth := 0, 0
+	var quote byte
+	for i := 0; i < len(inner); i++ {
+		switch ch := inner[i]; {
+		case quote == '"' && ch == '\\':
+			i++
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '[' || ch == '{':
+			depth++
+		case ch == ']' || ch == '}':
+			depth--
+		case ch == ',' && depth == 0:
+			values = append(values, strings.TrimSpace(inner[start:i]))
+			start = i + 1
+		}
+	}
+	// a trailing comma is allowed
+	if last := strings.TrimSpace(inner[start:]); last != "" {
+		values = append(values, last)
+	}
+	return values
+}
@@ -0,0 +1,432 @@
+package yamledit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lines(l ...string) string {
+	return strings.Join(l, "\n") + "\n"
+}
+
+func crlf(l ...string) string {
+	return strings.Join(l, "\r\n") + "\r\n"
+}
+
+func TestRead(t *testing.T) {
+	cases := []struct {
+		name   string
+		in     string
+		path   []string
+		kind   string
+		values []string
+	}{
+		{
+			name:   "inline go",
+			in:     lines("language: go", "go: 1.13 # pinned"),
+			path:   []string{"go"},
+			kind:   KindScalar,
+			values: []string{"1.13"},
+		},
+		{
+			name:   "quoted go",
+			in:     lines(`go: "1.13"`),
+			path:   []string{"go"},
+			kind:   KindScalar,
+			values: []string{"1.13"},
+		},
+		{
+			name:   "block list",
+			in:     lines("go:", "  - 1.12", "  # older", "  - '1.13'"),
+			path:   []string{"go"},
+			kind:   KindSequence,
+			values: []string{"1.12", "1.13"},
+		},
+		{
+			name:   "flow list",
+			in:     lines("go: [1.12, '1.13']"),
+			path:   []string{"go"},
+			kind:   KindSequence,
+			values: []string{"1.12", "1.13"},
+		},
+		{
+			name:   "flow list quoted commas",
+			in:     lines(`env: ["A=a,b", 'B=c, d', C=e]`),
+			path:   []string{"env"},
+			kind:   KindSequence,
+			values: []string{"A=a,b", "B=c, d", "C=e"},
+		},
+		{
+			name:   "env scalar",
+			in:     lines("env: GO111MODULE=on"),
+			path:   []string{"env"},
+			kind:   KindScalar,
+			values: []string{"GO111MODULE=on"},
+		},
+		{
+			name:   "env list",
+			in:     lines("env:", "- A=1", "- B=2"),
+			path:   []string{"env"},
+			kind:   KindSequence,
+			values: []string{"A=1", "B=2"},
+		},
+		{
+			name:   "env mapping",
+			in:     lines("env:", "  global:", "    - A=1", "  matrix:", "    - B=2"),
+			path:   []string{"env", "global"},
+			kind:   KindSequence,
+			values: []string{"A=1"},
+		},
+		{
+			name:   "env flow mapping",
+			in:     lines("env: {global: [A=1, 'B=2,3'], matrix: C=4}"),
+			path:   []string{"env", "global"},
+			kind:   KindSequence,
+			values: []string{"A=1", "B=2,3"},
+		},
+		{
+			name:   "matrix include",
+			in:     lines("matrix:", "  include:", "    - go: 1.12", "      env: A=1", "    - go: 1.13"),
+			path:   []string{"matrix", "include", "1", "go"},
+			kind:   KindScalar,
+			values: []string{"1.13"},
+		},
+		{
+			name:   "matrix include flow items",
+			in:     lines("matrix:", "  include: [{go: 1.12}, {go: '1.13', env: A=1}]"),
+			path:   []string{"matrix", "include", "1", "go"},
+			kind:   KindScalar,
+			values: []string{"1.13"},
+		},
+		{
+			name:   "block scalar",
+			in:     lines("script: |", "  make test", "    indented", "  make vet", "go: 1.13"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{"make test\n  indented\nmake vet\n"},
+		},
+		{
+			name:   "block scalar item",
+			in:     lines("script:", "  - >-", "    make", "    test", "  - make vet"),
+			path:   []string{"script"},
+			kind:   KindSequence,
+			values: []string{"make test", "make vet"},
+		},
+		{
+			name:   "literal strip",
+			in:     lines("script: |-", "  make", "  test", "", "go: 1.13"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{"make\ntest"},
+		},
+		{
+			name:   "literal keep",
+			in:     lines("script: |+", "  make", "", "", "go: 1.13"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{"make\n\n\n"},
+		},
+		{
+			name:   "literal keep at end",
+			in:     lines("script: |+ # kept", "  make", "", ""),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{"make\n\n\n"},
+		},
+		{
+			name:   "folded clip",
+			in:     lines("script: >", "  go test", "  ./...", "", "  go vet", "", "go: 1.13"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{"go test ./...\ngo vet\n"},
+		},
+		{
+			name:   "folded more indented",
+			in:     lines("description: >-", "  Runs", "  the tests:", "", "    make test", "  then", "  vet"),
+			path:   []string{"description"},
+			kind:   KindScalar,
+			values: []string{"Runs the tests:\n\n  make test\nthen vet"},
+		},
+		{
+			name:   "folded indentation indicator",
+			in:     lines("script: >2", "   indented", "  make"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{" indented\nmake\n"},
+		},
+		{
+			name:   "empty block scalar",
+			in:     lines("script: |", "go: 1.13"),
+			path:   []string{"script"},
+			kind:   KindScalar,
+			values: []string{""},
+		},
+		{
+			name:   "crlf",
+			in:     crlf("language: go", "go:", "  - 1.11", "  - '1.12'"),
+			path:   []string{"go"},
+			kind:   KindSequence,
+			values: []string{"1.11", "1.12"},
+		},
+		{
+			name:   "crlf inline",
+			in:     crlf("go: 1.11"),
+			path:   []string{"go"},
+			kind:   KindScalar,
+			values: []string{"1.11"},
+		},
+		{
+			name:   "no final newline",
+			in:     "language: go\ngo: 1.11",
+			path:   []string{"go"},
+			kind:   KindScalar,
+			values: []string{"1.11"},
+		},
+		{
+			name: "missing",
+			in:   lines("go: 1.13"),
+			path: []string{"env", "global"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Parse([]byte(c.in))
+			if kind := d.Kind(c.path...); kind != c.kind {
+				t.Errorf("Kind = %q, want %q", kind, c.kind)
+			}
+			if values, _ := d.Values(c.path...); !reflect.DeepEqual(values, c.values) {
+				t.Errorf("Values = %q, want %q", values, c.values)
+			}
+			if out := string(d.Bytes()); out != c.in {
+				t.Errorf("reading changed the document:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestKeys(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		path []string
+		keys []string
+	}{
+		{
+			name: "top level",
+			in:   lines("language: go", "# comment", "go: 1.13", "env:", "  - A=1"),
+			keys: []string{"language", "go", "env"},
+		},
+		{
+			name: "block mapping",
+			in:   lines("env:", "  matrix: A=1", "  global: B=2"),
+			path: []string{"env"},
+			keys: []string{"matrix", "global"},
+		},
+		{
+			name: "flow mapping",
+			in:   lines(`env: {matrix: A=1, "a,b": B=2}`),
+			path: []string{"env"},
+			keys: []string{"matrix", "a,b"},
+		},
+		{
+			name: "matrix item",
+			in:   lines("jobs:", "  include:", "    - stage: test", "      go: 1.13"),
+			path: []string{"jobs", "include", "0"},
+			keys: []string{"stage", "go"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Parse([]byte(c.in))
+			if keys := d.Keys(c.path...); !reflect.DeepEqual(keys, c.keys) {
+				t.Errorf("Keys = %q, want %q", keys, c.keys)
+			}
+		})
+	}
+}
+
+func TestEdit(t *testing.T) {
+	removeGovendor := func(d *Document, path ...string) {
+		d.RemoveItems(func(s string) bool { return strings.Contains(s, "govendor") }, path...)
+	}
+
+	cases := []struct {
+		name string
+		in   string
+		edit func(d *Document)
+		want string
+	}{
+		{
+			name: "set inline go keeps comment",
+			in:   lines("go: 1.13 # pinned"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "go") },
+			want: lines("go: 1.15 # pinned"),
+		},
+		{
+			name: "set block list",
+			in:   lines("go:", "  - 1.12", "  - 1.13", "script: make"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "go") },
+			want: lines("go:", "  - 1.15", "script: make"),
+		},
+		{
+			name: "set flow list",
+			in:   lines("go: [1.12, 1.13]"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "go") },
+			want: lines("go: [1.15]"),
+		},
+		{
+			name: "set missing key",
+			in:   lines("language: go", "", "# trailing"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "go") },
+			want: lines("language: go", "go: 1.15", "", "# trailing"),
+		},
+		{
+			name: "set block scalar",
+			in:   lines("script: |", "  make test", "go: 1.13"),
+			edit: func(d *Document) { d.SetValues([]string{"make"}, "script") },
+			want: lines("script: make", "go: 1.13"),
+		},
+		{
+			name: "set in flow mapping item",
+			in:   lines("matrix:", "  include: [{go: 1.12}, {go: 1.13, env: A=1}]"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "matrix", "include", "1", "go") },
+			want: lines("matrix:", "  include:", "    - {go: 1.12}", "    - go: 1.15", "      env: A=1"),
+		},
+		{
+			name: "set crlf",
+			in:   crlf("go:", "  - 1.12", "  - 1.13", "script: make"),
+			edit: func(d *Document) { d.SetValues([]string{"1.15", "1.16"}, "go") },
+			want: crlf("go:", "  - 1.15", "  - 1.16", "script: make"),
+		},
+		{
+			name: "set without final newline",
+			in:   "language: go\ngo: 1.13",
+			edit: func(d *Document) { d.SetValues([]string{"1.15"}, "go") },
+			want: "language: go\ngo: 1.15",
+		},
+		{
+			name: "append crlf",
+			in:   crlf("env: A=1", "go: 1.13"),
+			edit: func(d *Document) { d.Append("B=2", "env") },
+			want: crlf("env:", "  - A=1", "  - B=2", "go: 1.13"),
+		},
+		{
+			name: "append to empty document",
+			in:   "",
+			edit: func(d *Document) { d.Append("X=1", "env", "global") },
+			want: lines("env:", "  global:", "    - X=1"),
+		},
+		{
+			name: "append to comment only document",
+			in:   lines("# empty"),
+			edit: func(d *Document) { d.Append("X=1", "env", "global") },
+			want: lines("# empty", "env:", "  global:", "    - X=1"),
+		},
+		{
+			name: "append to scalar env",
+			in:   lines("env: A=1"),
+			edit: func(d *Document) { d.Append("B=2", "env") },
+			want: lines("env:", "  - A=1", "  - B=2"),
+		},
+		{
+			name: "append to flow list",
+			in:   lines(`env: ["A=1,2"]`),
+			edit: func(d *Document) { d.Append("B=2", "env") },
+			want: lines(`env: ["A=1,2", B=2]`),
+		},
+		{
+			name: "append to block scalar",
+			in:   lines("script: |", "  make", "go: 1.13"),
+			edit: func(d *Document) { d.Append("make vet", "script") },
+			want: lines("script:", "  - |", "    make", "  - make vet", "go: 1.13"),
+		},
+		{
+			name: "append to env flow mapping",
+			in:   lines("env: {matrix: [A=1]}"),
+			edit: func(d *Document) { d.Append("B=2", "env", "global") },
+			want: lines("env:", "  matrix: [A=1]", "  global:", "    - B=2"),
+		},
+		{
+			name: "nest env list",
+			in:   lines("env:", "  - A=1", "go: 1.13"),
+			edit: func(d *Document) {
+				d.Nest("matrix", "env")
+				d.Append("B=2", "env", "global")
+			},
+			want: lines("env:", "  matrix:", "    - A=1", "  global:", "    - B=2", "go: 1.13"),
+		},
+		{
+			name: "nest env scalar",
+			in:   lines("env: A=1"),
+			edit: func(d *Document) { d.Nest("matrix", "env") },
+			want: lines("env:", "  matrix: A=1"),
+		},
+		{
+			name: "remove from block list",
+			in:   lines("install:", "  - go get govendor", "  - make", "script: make"),
+			edit: func(d *Document) { removeGovendor(d, "install") },
+			want: lines("install:", "  - make", "script: make"),
+		},
+		{
+			name: "remove last item removes key",
+			in:   lines("install:", "  - go get govendor", "script: make"),
+			edit: func(d *Document) { removeGovendor(d, "install") },
+			want: lines("script: make"),
+		},
+		{
+			name: "remove from flow list with quoted commas",
+			in:   lines(`install: ["go get a,govendor", "make x,y"]`),
+			edit: func(d *Document) { removeGovendor(d, "install") },
+			want: lines(`install: ["make x,y"]`),
+		},
+		{
+			name: "remove block scalar",
+			in:   lines("install: |", "  go get govendor", "  govendor sync", "script: make"),
+			edit: func(d *Document) { removeGovendor(d, "install") },
+			want: lines("script: make"),
+		},
+		{
+			name: "remove keeps other keys of matrix item",
+			in:   lines("matrix:", "  include:", "    - install: govendor sync", "      go: 1.13"),
+			edit: func(d *Document) { removeGovendor(d, "matrix", "include", "0", "install") },
+			want: lines("matrix:", "  include:", "    - go: 1.13"),
+		},
+		{
+			name: "remove keeps nested keys of matrix item",
+			in:   lines("jobs:", "  include:", "    - install: govendor sync", "      # comment", "      env:", "        - A=1"),
+			edit: func(d *Document) { removeGovendor(d, "jobs", "include", "0", "install") },
+			want: lines("jobs:", "  include:", "    -", "      # comment", "      env:", "        - A=1"),
+		},
+		{
+			name: "remove empties matrix item",
+			in:   lines("matrix:", "  include:", "    - install: govendor sync", "    - go: 1.13"),
+			edit: func(d *Document) { removeGovendor(d, "matrix", "include", "0", "install") },
+			want: lines("matrix:", "  include:", "    - go: 1.13"),
+		},
+		{
+			name: "remove empties matrix",
+			in:   lines("go: 1.13", "matrix:", "  include:", "    - install:", "        - govendor sync", "script: make"),
+			edit: func(d *Document) { removeGovendor(d, "matrix", "include", "0", "install") },
+			want: lines("go: 1.13", "script: make"),
+		},
+		{
+			name: "no match keeps flow mapping",
+			in:   lines("env: {global: [A=1]}"),
+			edit: func(d *Document) { removeGovendor(d, "env", "global") },
+			want: lines("env: {global: [A=1]}"),
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			d := Parse([]byte(c.in))
+			c.edit(d)
+			if out := string(d.Bytes()); out != c.want {
+				t.Errorf("got:\n%s\nwant:\n%s", out, c.want)
+			}
+		})
+	}
+}